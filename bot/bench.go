@@ -0,0 +1,180 @@
+package bot
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"slices"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/redwookcreek/snake/snake"
+)
+
+// Size of a game board, including the boarder
+type Size struct {
+	Width  int `json:"width"`
+	Height int `json:"height"`
+}
+
+// Parse a board size like 20x10, width first
+func ParseSize(s string) (Size, error) {
+	w, h, ok := strings.Cut(s, "x")
+	width, err1 := strconv.Atoi(w)
+	height, err2 := strconv.Atoi(h)
+	if !ok || err1 != nil || err2 != nil {
+		return Size{}, fmt.Errorf("invalid board size %q, should be like 20x20", s)
+	}
+	if width < 3 || height < 3 {
+		return Size{}, fmt.Errorf("board size %q too small, should be at least 3x3", s)
+	}
+	return Size{width, height}, nil
+}
+
+func (s Size) String() string {
+	return fmt.Sprintf("%dx%d", s.Width, s.Height)
+}
+
+// What to benchmark, every bot plays Games games on every board size
+type BenchConfig struct {
+	Bots  []string
+	Sizes []Size
+	Games int
+
+	// Game i is played with seed Seed + i, so that every bot
+	// plays the same games
+	Seed uint64
+}
+
+// Summary of the games one bot played on one board size
+type BenchResult struct {
+	Bot   string `json:"bot"`
+	Size  Size   `json:"size"`
+	Games int    `json:"games"`
+
+	MeanScore   float64 `json:"mean_score"`
+	MedianScore float64 `json:"median_score"`
+
+	// Number of ticks the snake survived
+	MeanTicks   float64 `json:"mean_ticks"`
+	MedianTicks float64 `json:"median_ticks"`
+
+	// Share of games won by filling the board
+	WinRate float64 `json:"win_rate"`
+
+	// Ticks played for each apple eaten
+	MovesPerApple float64 `json:"moves_per_apple"`
+}
+
+// Returns the number of ticks a game is allowed to go without eating
+// an apple before it is stopped, so that bots going in circles end
+func starve_ticks(size Size) int {
+	return 2 * size.Width * size.Height
+}
+
+// Play one headless game with a bot, returns the engine at the end of it
+func bench_game(name string, size Size, seed uint64) (*snake.Engine, error) {
+	c, err := New(name)
+	if err != nil {
+		return nil, err
+	}
+	e := snake.NewEngine(snake.CreateSnake(size.Height, size.Width, snake.WithSeed(seed)))
+	hungry := 0
+	for !e.State.GameOver && hungry < starve_ticks(size) {
+		if e.StepController(c).Has(snake.EVENT_ATE_APPLE) {
+			hungry = 0
+		} else {
+			hungry += 1
+		}
+	}
+	return e, nil
+}
+
+// Run all the games of the benchmark
+func Bench(config BenchConfig) ([]BenchResult, error) {
+	if config.Games <= 0 {
+		return nil, fmt.Errorf("invalid number of games %d, should be at least 1", config.Games)
+	}
+	results := []BenchResult{}
+	for _, name := range config.Bots {
+		for _, size := range config.Sizes {
+			scores := make([]int, 0, config.Games)
+			ticks := make([]int, 0, config.Games)
+			won := 0
+			for i := 0; i < config.Games; i++ {
+				e, err := bench_game(name, size, config.Seed+uint64(i))
+				if err != nil {
+					return nil, err
+				}
+				scores = append(scores, e.State.Score)
+				ticks = append(ticks, e.Ticks)
+				if e.State.GameWon {
+					won += 1
+				}
+			}
+			results = append(results, summarize(name, size, scores, ticks, won))
+		}
+	}
+	return results, nil
+}
+
+func summarize(name string, size Size, scores, ticks []int, won int) BenchResult {
+	games := len(scores)
+	result := BenchResult{Bot: name, Size: size, Games: games}
+	if games == 0 {
+		return result
+	}
+	result.MeanScore = mean(scores)
+	result.MedianScore = median(scores)
+	result.MeanTicks = mean(ticks)
+	result.MedianTicks = median(ticks)
+	result.WinRate = float64(won) / float64(games)
+	if apples := sum(scores); apples > 0 {
+		result.MovesPerApple = float64(sum(ticks)) / float64(apples)
+	}
+	return result
+}
+
+func sum(values []int) int {
+	total := 0
+	for _, v := range values {
+		total += v
+	}
+	return total
+}
+
+func mean(values []int) float64 {
+	return float64(sum(values)) / float64(len(values))
+}
+
+func median(values []int) float64 {
+	sorted := slices.Clone(values)
+	slices.Sort(sorted)
+	mid := len(sorted) / 2
+	if len(sorted)%2 == 0 {
+		return float64(sorted[mid-1]+sorted[mid]) / 2
+	}
+	return float64(sorted[mid])
+}
+
+// Write the results as a table
+func WriteTable(w io.Writer, results []BenchResult) error {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', tabwriter.AlignRight)
+	fmt.Fprintln(tw, "bot\tboard\tgames\tmean score\tmedian score\tmean ticks\tmedian ticks\twin rate\tmoves/apple\t")
+	for _, r := range results {
+		fmt.Fprintf(tw, "%s\t%s\t%d\t%.1f\t%.1f\t%.1f\t%.1f\t%.1f%%\t%.1f\t\n",
+			r.Bot, r.Size, r.Games,
+			r.MeanScore, r.MedianScore,
+			r.MeanTicks, r.MedianTicks,
+			r.WinRate*100, r.MovesPerApple)
+	}
+	return tw.Flush()
+}
+
+// Write the results as JSON
+func WriteJSON(w io.Writer, results []BenchResult) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(results)
+}
@@ -0,0 +1,66 @@
+package bot
+
+import (
+	"bytes"
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseSize(t *testing.T) {
+	size, err := ParseSize("20x10")
+	assert.NoError(t, err)
+	assert.Equal(t, Size{20, 10}, size)
+	assert.Equal(t, "20x10", size.String())
+
+	for _, s := range []string{"", "20", "20x", "x10", "ax10", "2x10"} {
+		_, err := ParseSize(s)
+		assert.Error(t, err, s)
+	}
+}
+
+func TestSummarize(t *testing.T) {
+	r := summarize("bfs", Size{10, 10}, []int{1, 4, 3, 8}, []int{10, 40, 30, 80}, 1)
+	assert.Equal(t, 4, r.Games)
+	assert.Equal(t, 4.0, r.MeanScore)
+	assert.Equal(t, 3.5, r.MedianScore)
+	assert.Equal(t, 40.0, r.MeanTicks)
+	assert.Equal(t, 35.0, r.MedianTicks)
+	assert.Equal(t, 0.25, r.WinRate)
+	assert.Equal(t, 10.0, r.MovesPerApple)
+}
+
+func TestBench(t *testing.T) {
+	config := BenchConfig{NAMES, []Size{{8, 8}, {9, 9}}, 3, 1}
+	results, err := Bench(config)
+	assert.NoError(t, err)
+	assert.Len(t, results, len(NAMES)*2)
+
+	_, err = Bench(BenchConfig{NAMES, []Size{{8, 8}}, -1, 1})
+	assert.Error(t, err)
+	_, err = Bench(BenchConfig{NAMES, []Size{{8, 8}}, 0, 1})
+	assert.Error(t, err)
+
+	// Same seeds give the same results
+	again, _ := Bench(config)
+	assert.Equal(t, results, again)
+
+	// Hamiltonian cycle always fills the 8x8 board
+	assert.Equal(t, "hamilton", results[4].Bot)
+	assert.Equal(t, 1.0, results[4].WinRate)
+
+	var table bytes.Buffer
+	assert.NoError(t, WriteTable(&table, results))
+	assert.Contains(t, table.String(), "hamilton")
+
+	var out bytes.Buffer
+	assert.NoError(t, WriteJSON(&out, results))
+	decoded := []BenchResult{}
+	assert.NoError(t, json.Unmarshal(out.Bytes(), &decoded))
+	assert.Equal(t, results, decoded)
+
+	config.Bots = []string{"nobody"}
+	_, err = Bench(config)
+	assert.Error(t, err)
+}
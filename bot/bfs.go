@@ -0,0 +1,42 @@
+package bot
+
+import "github.com/redwookcreek/snake/snake"
+
+// Follows the shortest path to the apple around the snake body,
+// if the apple cannot be reached, moves to where there is the most room
+type BFS struct{}
+
+func (b *BFS) NextDirection(v snake.SnakeView) int {
+	if dir, ok := shortest_path(v, v.Apple()); ok {
+		return dir
+	}
+	return most_room(v)
+}
+
+// Returns the first direction of the shortest path from the head to dst
+func shortest_path(v snake.SnakeView, dst snake.Point) (int, bool) {
+	// first direction taken to reach each visited cell
+	first_dir := map[snake.Point]int{}
+	queue := []snake.Point{}
+	for _, dir := range safe_directions(v) {
+		next := v.Neighbor(v.Head(), dir)
+		first_dir[next] = dir
+		queue = append(queue, next)
+	}
+	for len(queue) > 0 {
+		cur := queue[0]
+		queue = queue[1:]
+		if cur == dst {
+			return first_dir[cur], true
+		}
+		for _, dir := range snake.DIRECTIONS {
+			next := v.Neighbor(cur, dir)
+			if _, visited := first_dir[next]; visited || v.Blocked(next) {
+				continue
+			}
+			first_dir[next] = first_dir[cur]
+			queue = append(queue, next)
+		}
+	}
+	return 0, false
+}
@@ -0,0 +1,74 @@
+// Package bot has controllers that play snake without a player
+package bot
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/redwookcreek/snake/snake"
+)
+
+// Names of the built-in bots, selectable from the command line
+var NAMES = []string{"greedy", "bfs", "hamilton"}
+
+// Create a built-in bot by name
+func New(name string) (snake.Controller, error) {
+	switch name {
+	case "greedy":
+		return &Greedy{}, nil
+	case "bfs":
+		return &BFS{}, nil
+	case "hamilton":
+		return &Hamilton{}, nil
+	}
+	return nil, fmt.Errorf("unknown bot %q, should be one of %s", name, strings.Join(NAMES, ", "))
+}
+
+// Returns the directions the snake can turn to without ending the game
+// in the next tick, the snake cannot reverse
+func safe_directions(v snake.SnakeView) []int {
+	dirs := make([]int, 0, len(snake.DIRECTIONS))
+	for _, dir := range snake.DIRECTIONS {
+		if dir == snake.Opposite(v.Direction()) {
+			continue
+		}
+		if !v.Blocked(v.Neighbor(v.Head(), dir)) {
+			dirs = append(dirs, dir)
+		}
+	}
+	return dirs
+}
+
+// Number of cells reachable from p without crossing the snake or walls
+func reachable(v snake.SnakeView, p snake.Point) int {
+	if v.Blocked(p) {
+		return 0
+	}
+	visited := map[snake.Point]bool{p: true}
+	queue := []snake.Point{p}
+	for len(queue) > 0 {
+		cur := queue[0]
+		queue = queue[1:]
+		for _, dir := range snake.DIRECTIONS {
+			next := v.Neighbor(cur, dir)
+			if !visited[next] && !v.Blocked(next) {
+				visited[next] = true
+				queue = append(queue, next)
+			}
+		}
+	}
+	return len(visited)
+}
+
+// Turn to the safe direction with the most room left,
+// used when there is no better move
+func most_room(v snake.SnakeView) int {
+	best, best_room := v.Direction(), -1
+	for _, dir := range safe_directions(v) {
+		room := reachable(v, v.Neighbor(v.Head(), dir))
+		if room > best_room {
+			best, best_room = dir, room
+		}
+	}
+	return best
+}
@@ -0,0 +1,95 @@
+package bot
+
+import (
+	"testing"
+
+	"github.com/redwookcreek/snake/snake"
+	"github.com/stretchr/testify/assert"
+)
+
+// Play a seeded game with a bot until it ends or max_ticks is reached
+func play(c snake.Controller, height, width int, seed uint64, max_ticks int) *snake.Engine {
+	e := snake.NewEngine(snake.CreateSnake(height, width, snake.WithSeed(seed)))
+	for !e.State.GameOver && e.Ticks < max_ticks {
+		e.StepController(c)
+	}
+	return e
+}
+
+// Build the cycle inside the boarder of a board
+func boarder_cycle(width, height int) []int {
+	return build_cycle(width, snake.Point{X: 1, Y: 1}, snake.Point{X: width - 1, Y: height - 1})
+}
+
+func assert_cycle(t *testing.T, width, height int) {
+	cycle := boarder_cycle(width, height)
+	assert.NotNil(t, cycle)
+
+	// Starting from any cell inside the boarder, the cycle visits
+	// every such cell once and comes back
+	start := snake.Point{X: 1, Y: 1}
+	visited := map[snake.Point]bool{}
+	p := start
+	for i := 0; i < (width-2)*(height-2); i++ {
+		assert.False(t, visited[p])
+		visited[p] = true
+		assert.True(t, p.X > 0 && p.X < width-1 && p.Y > 0 && p.Y < height-1, "%v outside of board", p)
+		p = p.Next(cycle[p.Y*width+p.X])
+	}
+	assert.Equal(t, start, p)
+}
+
+func TestBuildCycle(t *testing.T) {
+	assert_cycle(t, 6, 6)
+	assert_cycle(t, 10, 7)
+	assert_cycle(t, 7, 10)
+	assert_cycle(t, 4, 4)
+
+	// No cycle on odd by odd boards
+	assert.Nil(t, boarder_cycle(7, 7))
+}
+
+func TestHamiltonWins(t *testing.T) {
+	e := play(&Hamilton{}, 8, 8, 1, 100000)
+	assert.True(t, e.State.GameWon)
+	assert.Equal(t, 6*6-1, e.State.Score)
+}
+
+func TestBotsWrap(t *testing.T) {
+	ss := snake.CreateSnake(6, 6, snake.WithSeed(1), snake.WithRules(snake.Rules{Wrap: true}))
+	e := snake.NewEngine(ss)
+	b := &Hamilton{}
+	for !e.State.GameOver && e.Ticks < 100000 {
+		e.StepController(b)
+	}
+	// The whole board is the play area
+	assert.True(t, e.State.GameWon)
+	assert.Equal(t, 6*6-1, e.State.Score)
+
+	// Over the edge is closer
+	ss = snake.CreateSnake(10, 10, snake.WithRules(snake.Rules{Wrap: true}))
+	ss.Apple = snake.Point{X: 5, Y: 1}
+	ss.SnakeBody[0].Cord = snake.Point{X: 5, Y: 8}
+	assert.Equal(t, 3, ss.View().Distance(ss.View().Head(), ss.Apple))
+	assert.Equal(t, snake.DOWN, (&Greedy{}).NextDirection(ss.View()))
+}
+
+func TestBotsEatApples(t *testing.T) {
+	for _, name := range NAMES {
+		c, err := New(name)
+		assert.NoError(t, err)
+		e := play(c, 12, 12, 3, 5000)
+		assert.Greater(t, e.State.Score, 5, name)
+	}
+
+	_, err := New("nobody")
+	assert.Error(t, err)
+}
+
+func TestBFSFindsPath(t *testing.T) {
+	ss := snake.CreateSnake(10, 10)
+	ss.Apple = snake.Point{X: 2, Y: 5}
+	// snake at 5, 5 moving down, the apple is to the left
+	assert.Equal(t, snake.LEFT, (&BFS{}).NextDirection(ss.View()))
+	assert.Equal(t, snake.LEFT, (&Greedy{}).NextDirection(ss.View()))
+}
@@ -0,0 +1,17 @@
+package bot
+
+import "github.com/redwookcreek/snake/snake"
+
+// Turns toward the apple, only avoiding moves that end the game right away
+type Greedy struct{}
+
+func (b *Greedy) NextDirection(v snake.SnakeView) int {
+	best, best_dist := v.Direction(), -1
+	for _, dir := range safe_directions(v) {
+		dist := v.Distance(v.Neighbor(v.Head(), dir), v.Apple())
+		if best_dist < 0 || dist < best_dist {
+			best, best_dist = dir, dist
+		}
+	}
+	return best
+}
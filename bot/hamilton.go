@@ -0,0 +1,105 @@
+package bot
+
+import "github.com/redwookcreek/snake/snake"
+
+// Follows a Hamiltonian cycle through every cell of the play area.
+// The snake never runs into itself and always fills the board, slowly.
+// Play areas with an odd number of rows and columns, or with obstacles,
+// have no such cycle, BFS is used instead
+type Hamilton struct {
+	// Direction to leave each cell of the cycle,
+	// indexed by y * width + x, built for the play area on first use
+	cycle     []int
+	width     int
+	lo        snake.Point
+	hi        snake.Point
+	obstacles bool
+
+	fallback BFS
+}
+
+func (b *Hamilton) NextDirection(v snake.SnakeView) int {
+	lo, hi := v.PlayArea()
+	if b.width != v.Width() || b.lo != lo || b.hi != hi || b.obstacles != v.HasObstacles() {
+		b.width, b.lo, b.hi, b.obstacles = v.Width(), lo, hi, v.HasObstacles()
+		b.cycle = nil
+		if !b.obstacles {
+			b.cycle = build_cycle(b.width, lo, hi)
+		}
+	}
+	if b.cycle == nil {
+		return b.fallback.NextDirection(v)
+	}
+
+	head := v.Head()
+	dir := b.cycle[head.Y*b.width+head.X]
+	if dir == snake.Opposite(v.Direction()) || v.Blocked(v.Neighbor(head, dir)) {
+		// Only at the start of the game, when the snake is
+		// not on its way along the cycle yet
+		return most_room(v)
+	}
+	return dir
+}
+
+// Build a cycle through the cells of the play area from lo to hi
+// on a board width cells wide, returns nil if there is none
+func build_cycle(width int, lo, hi snake.Point) []int {
+	w, h := hi.X-lo.X, hi.Y-lo.Y
+	transposed := false
+	if h%2 != 0 {
+		if w%2 != 0 {
+			return nil
+		}
+		w, h = h, w
+		transposed = true
+	}
+	if w < 2 || h < 2 {
+		return nil
+	}
+
+	cycle := make([]int, width*hi.Y)
+	for y := 0; y < h; y++ {
+		for x := 0; x < w; x++ {
+			dir := cycle_direction(x, y, w, h)
+			cx, cy := x, y
+			if transposed {
+				// swap x and y, moving right becomes moving down
+				cx, cy = y, x
+				dir = _TRANSPOSED_DIR[dir]
+			}
+			cycle[(cy+lo.Y)*width+cx+lo.X] = dir
+		}
+	}
+	return cycle
+}
+
+var _TRANSPOSED_DIR = map[int]int{
+	snake.UP:    snake.LEFT,
+	snake.LEFT:  snake.UP,
+	snake.DOWN:  snake.RIGHT,
+	snake.RIGHT: snake.DOWN,
+}
+
+// Direction of cell x, y on the cycle of a w by h grid, h must be even.
+// The first row goes right, the rows below zigzag over all columns
+// but the first one, and the first column leads back up to the first row
+func cycle_direction(x, y, w, h int) int {
+	switch {
+	case y == 0 && x < w-1:
+		return snake.RIGHT
+	case x == 0:
+		return snake.UP
+	case y%2 == 0:
+		// even rows below the first go right
+		if x == w-1 {
+			return snake.DOWN
+		}
+		return snake.RIGHT
+	default:
+		// odd rows go left, the last row goes all the way to the first column
+		if x == 1 && y < h-1 {
+			return snake.DOWN
+		}
+		return snake.LEFT
+	}
+}
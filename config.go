@@ -0,0 +1,104 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/redwookcreek/snake/snake"
+)
+
+// Define the flags of the settings kept in the config file
+func config_flags(flags *flag.FlagSet, config *snake.Config) {
+	flags.IntVar(&config.Width, "width", config.Width, "board width in cells")
+	flags.IntVar(&config.Height, "height", config.Height, "board height in cells")
+	flags.Var(&size_value{&config.WindowWidth, &config.WindowHeight}, "window", "window size in pixels, width x height")
+	flags.BoolVar(&config.Fullscreen, "fullscreen", config.Fullscreen, "play in full screen")
+	flags.StringVar(&config.Speed, "speed", config.Speed, "how fast the snake moves: "+difficulty_names())
+	flags.BoolVar(&config.Progressive, "progressive", config.Progressive, "speed up with every apple eaten")
+	flags.BoolVar(&config.Wrap, "wrap", config.Wrap, "no boarder, the snake wraps around the edges")
+	flags.IntVar(&config.Lives, "lives", config.Lives, "number of lives")
+	flags.BoolVar(&config.Items, "items", config.Items, "golden apples, shrink pills and power-ups")
+	flags.BoolVar(&config.Timed, "timed", config.Timed, "apples go away in time, eating them fast scores more")
+	flags.Uint64Var(&config.Seed, "seed", config.Seed, "seed of the first game, random if 0")
+	flags.StringVar(&config.Level, "level", config.Level, "play a bundled level by name, or a level file")
+}
+
+// Returns the settings of the config file with the flags set on the
+// command line applied on top of them, and the config file. The default
+// config file is read if path is empty, it is fine if there is none
+func load_config(path string, flags *flag.FlagSet) (snake.Config, string, error) {
+	config := snake.DEFAULT_CONFIG
+	required := path != ""
+	if !required {
+		var err error
+		if path, err = snake.DefaultConfigPath(); err != nil {
+			log.Printf("No config file: %v", err)
+		}
+	}
+	if path != "" {
+		loaded, err := snake.LoadConfig(path)
+		if err == nil {
+			config = loaded
+		} else if required || !errors.Is(err, os.ErrNotExist) {
+			return config, path, err
+		}
+	}
+
+	if flags != nil {
+		overrides := flag.NewFlagSet(flags.Name(), flag.ContinueOnError)
+		config_flags(overrides, &config)
+		var err error
+		flags.Visit(func(f *flag.Flag) {
+			if overrides.Lookup(f.Name) != nil && err == nil {
+				err = overrides.Set(f.Name, f.Value.String())
+			}
+		})
+		if err != nil {
+			return config, path, err
+		}
+	}
+	if err := config.Validate(); err != nil {
+		return config, path, fmt.Errorf("invalid settings:\n%w", err)
+	}
+	return config, path, nil
+}
+
+// Returns the settings of the default config file, and the file, for
+// the commands that do not take them as flags. The file is empty if it
+// could not be read, so that the options do not overwrite it
+func default_config() (snake.Config, string) {
+	config, path, err := load_config("", nil)
+	if err != nil {
+		log.Print(err)
+		return snake.DEFAULT_CONFIG, ""
+	}
+	return config, path
+}
+
+// A flag like 640x480 setting a width and a height
+type size_value struct {
+	width, height *int
+}
+
+func (v *size_value) String() string {
+	if v.width == nil {
+		return ""
+	}
+	return fmt.Sprintf("%dx%d", *v.width, *v.height)
+}
+
+func (v *size_value) Set(s string) error {
+	w, h, ok := strings.Cut(s, "x")
+	width, err1 := strconv.Atoi(w)
+	height, err2 := strconv.Atoi(h)
+	if !ok || err1 != nil || err2 != nil {
+		return fmt.Errorf("invalid size %q, should be like 640x480", s)
+	}
+	*v.width, *v.height = width, height
+	return nil
+}
@@ -1,4 +1,4 @@
-package snake
+package game
 
 import (
 	"bytes"
@@ -11,6 +11,7 @@ import (
 	"github.com/hajimehoshi/ebiten/v2"
 	"github.com/hajimehoshi/ebiten/v2/ebitenutil"
 	"github.com/hajimehoshi/ebiten/v2/text/v2"
+	"github.com/redwookcreek/snake/snake"
 )
 
 var (
@@ -51,6 +52,18 @@ var (
 
 	BOARDER_COLOR = color.White
 
+	// Dims the board behind a message
+	OVERLAY_COLOR = color.RGBA{0, 0, 0, 0xc0}
+
+	// Colors of the items, the golden apple is the apple image tinted
+	ITEM_COLORS = map[snake.ItemKind]color.Color{
+		snake.ITEM_GOLDEN_APPLE:  color.RGBA{0xff, 0xd7, 0x00, 0xff},
+		snake.ITEM_SHRINK_PILL:   color.RGBA{0xc0, 0x40, 0xff, 0xff},
+		snake.ITEM_SPEED_UP:      color.RGBA{0xff, 0x80, 0x00, 0xff},
+		snake.ITEM_SLOW_DOWN:     color.RGBA{0x40, 0x90, 0xff, 0xff},
+		snake.ITEM_INVINCIBILITY: color.RGBA{0x40, 0xff, 0xe0, 0xff},
+	}
+
 	sprite_cell_size = 320 / 5
 )
 
@@ -61,7 +74,7 @@ func init() {
 	}
 	M_PLUS_FACE_SCOURCE = s
 
-	ASSETS_SPRITE, _, err = ebitenutil.NewImageFromFile("snake/snake-graphics.png")
+	ASSETS_SPRITE, _, err = ebitenutil.NewImageFromReader(bytes.NewReader(asset_sprite_file))
 	if err != nil {
 		log.Fatal(err)
 	}
@@ -86,22 +99,22 @@ func init() {
 	APPLE_IMG = sprite_sub_image(3, 0)
 
 	BODY_PART_TO_IMG_MAP = map[int]*ebiten.Image{
-		BODY_PART_HEAD_DOWN:  SNAKE_HEAD_DOWN_IMG,
-		BODY_PART_HEAD_UP:    SNAKE_HEAD_UP_IMG,
-		BODY_PART_HEAD_LEFT:  SNAKE_HEAD_LEFT_IMG,
-		BODY_PART_HEAD_RIGHT: SNAKE_HEAD_RIGHT_IMG,
-
-		BODY_PART_TAIL_DOWN:  SNAKE_TAIL_DOWN_IMG,
-		BODY_PART_TAIL_UP:    SNAKE_TAIL_UP_IMG,
-		BODY_PART_TAIL_LEFT:  SNAKE_TAIL_LEFT_IMG,
-		BODY_PART_TAIL_RIGHT: SNAKE_TAIL_RIGHT_IMG,
-
-		BODY_PART_I:       SNAKE_BODY_VERTICAL_IMG,
-		BODY_PART_H:       SNAKE_BODY_HORIZONTAL_IMG,
-		BODY_PART_BODY_L:  SNAKE_BODY_L_IMG,
-		BODY_PART_BODY_L1: SNAKE_BODY_L1_IMG,
-		BODY_PART_BODY_L2: SNAKE_BODY_L2_IMG,
-		BODY_PART_BODY_L3: SNAKE_BODY_L3_IMG,
+		snake.BODY_PART_HEAD_DOWN:  SNAKE_HEAD_DOWN_IMG,
+		snake.BODY_PART_HEAD_UP:    SNAKE_HEAD_UP_IMG,
+		snake.BODY_PART_HEAD_LEFT:  SNAKE_HEAD_LEFT_IMG,
+		snake.BODY_PART_HEAD_RIGHT: SNAKE_HEAD_RIGHT_IMG,
+
+		snake.BODY_PART_TAIL_DOWN:  SNAKE_TAIL_DOWN_IMG,
+		snake.BODY_PART_TAIL_UP:    SNAKE_TAIL_UP_IMG,
+		snake.BODY_PART_TAIL_LEFT:  SNAKE_TAIL_LEFT_IMG,
+		snake.BODY_PART_TAIL_RIGHT: SNAKE_TAIL_RIGHT_IMG,
+
+		snake.BODY_PART_I:       SNAKE_BODY_VERTICAL_IMG,
+		snake.BODY_PART_H:       SNAKE_BODY_HORIZONTAL_IMG,
+		snake.BODY_PART_BODY_L:  SNAKE_BODY_L_IMG,
+		snake.BODY_PART_BODY_L1: SNAKE_BODY_L1_IMG,
+		snake.BODY_PART_BODY_L2: SNAKE_BODY_L2_IMG,
+		snake.BODY_PART_BODY_L3: SNAKE_BODY_L3_IMG,
 	}
 }
 
@@ -0,0 +1,127 @@
+package game
+
+import (
+	"fmt"
+	"log"
+
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/redwookcreek/snake/snake"
+)
+
+// Seconds the level complete screen is shown before the next level starts
+const LEVEL_TRANSITION_SECONDS = 3
+
+// A campaign played by the game
+type campaign_state struct {
+	*snake.Campaign
+
+	// Furthest level unlocked, saved to progress_path if not empty
+	progress      snake.CampaignProgress
+	progress_path string
+
+	// Frames left on the level complete screen, 0 while playing
+	transition_frames int
+
+	// True after completing the last level
+	finished bool
+}
+
+// True if the current level is complete and the next one is not started yet
+func (c *campaign_state) level_complete() bool {
+	return c != nil && (c.transition_frames > 0 || c.finished)
+}
+
+// Create a game playing the campaign from its current level. Reaching
+// a level unlocks it in the progress, which is saved to progress_path
+func CreateCampaignGame(c *snake.Campaign, progress snake.CampaignProgress, progress_path string, opts ...snake.SnakeOption) *Game {
+	opts = append(opts, snake.WithLevel(c.Level()))
+	g := CreateGame(0, 0, opts...)
+	g.campaign = &campaign_state{c, progress, progress_path, 0, false}
+	g.unlock_level(c.Current)
+	return g
+}
+
+// Returns the progress saved to progress_path, nothing unlocked if there is none
+func load_progress(progress_path string) snake.CampaignProgress {
+	if progress_path == "" {
+		return snake.CampaignProgress{}
+	}
+	progress, err := snake.LoadProgress(progress_path)
+	if err != nil {
+		log.Printf("Failed to load campaign progress: %v", err)
+	}
+	return progress
+}
+
+// Unlock the level with index level and save the progress
+func (g *Game) unlock_level(level int) {
+	c := g.campaign
+	if !c.progress.Unlock(level) || c.progress_path == "" {
+		return
+	}
+	if err := snake.SaveProgress(c.progress_path, c.progress); err != nil {
+		log.Printf("Failed to save campaign progress: %v", err)
+	}
+}
+
+// Called when the score reaches the goal of the level
+func (g *Game) complete_level() {
+	c := g.campaign
+	// the level is done, it is not resumed from the save
+	g.remove_save()
+	if c.LastLevel() {
+		c.Advance(g.SnakeState.Score)
+		c.finished = true
+		g.finish_game()
+		return
+	}
+	g.unlock_level(c.Current + 1)
+	c.transition_frames = LEVEL_TRANSITION_SECONDS * ebiten.TPS()
+}
+
+// Count down the level complete screen, Enter skips it
+func (g *Game) update_level_transition(keys []ebiten.Key) {
+	c := g.campaign
+	c.transition_frames -= 1
+	for _, key := range keys {
+		if key == ebiten.KeyEnter || key == ebiten.KeySpace {
+			c.transition_frames = 0
+		}
+	}
+	if c.transition_frames <= 0 {
+		c.transition_frames = 0
+		c.Advance(g.SnakeState.Score)
+		// lives left carry over to the next level
+		g.start_game(c.Level(), g.SnakeState.Lives)
+	}
+}
+
+// Start the campaign over from the first level
+func (g *Game) restart_campaign() {
+	c := g.campaign
+	c.Current = 0
+	c.TotalScore = 0
+	c.finished = false
+	g.start_game(c.Level(), 0)
+}
+
+func draw_campaign_info(screen *ebiten.Image, c *campaign_state, x, y int) {
+	draw_game_info(screen, x, y, fmt.Sprintf(
+		"Level %d/%d: %s  Goal: %d  Total: %d",
+		c.Current+1, len(c.Levels), c.Level().Name, c.Goal(), c.TotalScore))
+}
+
+func draw_level_complete_screen(screen *ebiten.Image, c *campaign_state, score int) {
+	draw_overlay(screen)
+	x, y := screen.Bounds().Dx()/2-100, screen.Bounds().Dy()/2
+	if c.finished {
+		draw_game_info(screen, x, y, "Campaign complete!")
+		draw_game_info(screen, x, y+20, fmt.Sprintf("Total score: %d", c.TotalScore))
+		return
+	}
+	next := c.Levels[c.Current+1]
+	draw_game_info(screen, x, y, fmt.Sprintf("Level %d complete!", c.Current+1))
+	draw_game_info(screen, x, y+20, fmt.Sprintf("Total score: %d", c.TotalScore+score))
+	draw_game_info(screen, x, y+40, fmt.Sprintf("Next: %s, goal %d", next.Name, next.GoalScore()))
+	draw_game_info(screen, x, y+60, "Press Enter to start")
+}
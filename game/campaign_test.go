@@ -0,0 +1,61 @@
+package game
+
+import (
+	"testing"
+
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/redwookcreek/snake/snake"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRestartDuringLevelTransition(t *testing.T) {
+	c, err := snake.NewCampaign(snake.Levels()[:2], 0)
+	assert.NoError(t, err)
+	g := CreateCampaignGame(c, snake.CampaignProgress{}, "", snake.WithLives(3))
+	g.SnakeState.Score = c.Goal()
+	g.SnakeState.Lives = 2
+	g.complete_level()
+	assert.True(t, g.campaign.level_complete())
+
+	// Restarting does not drop the completed level
+	g.RestartGame()
+	assert.True(t, g.campaign.level_complete())
+	assert.Equal(t, c.Goal(), g.SnakeState.Score)
+
+	g.update_level_transition([]ebiten.Key{ebiten.KeyEnter})
+	assert.False(t, g.campaign.level_complete())
+	assert.Equal(t, 1, c.Current)
+	assert.Equal(t, snake.Levels()[0].GoalScore(), c.TotalScore)
+	// lives left carry over
+	assert.Equal(t, 2, g.SnakeState.Lives)
+}
+
+func TestFinishCampaign(t *testing.T) {
+	c, err := snake.NewCampaign(snake.Levels()[:2], 1)
+	assert.NoError(t, err)
+	c.TotalScore = 20
+	g := CreateCampaignGame(c, snake.CampaignProgress{}, "")
+	g.high_scores = &high_score_state{table: snake.HighScores{Boards: map[string][]snake.HighScore{}}, rank: -1}
+	g.SnakeState.Score = c.Goal()
+	g.complete_level()
+	assert.True(t, g.campaign.finished)
+	assert.False(t, g.playing())
+
+	// The campaign is a won game, its total score goes on the table
+	total := 20 + c.Goal()
+	assert.Equal(t, total, c.TotalScore)
+	assert.Equal(t, snake.GameStats{Played: 1, Won: 1, BestScore: total}, g.Stats)
+	assert.True(t, g.high_scores.entering_name())
+	assert.Equal(t, total, g.high_scores.score)
+	key, score := g.high_score()
+	assert.Equal(t, snake.CampaignHighScoreKey(g.SnakeState.Rules), key)
+	assert.Equal(t, total, score)
+}
+
+func TestCampaignRefusesWrap(t *testing.T) {
+	config := snake.DEFAULT_CONFIG
+	config.Wrap = true
+	a := NewApp(config)
+	assert.ErrorContains(t, a.PlayCampaign(false), "wraps around")
+	assert.Nil(t, a.game)
+}
@@ -0,0 +1,124 @@
+package game
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/hajimehoshi/ebiten/v2/inpututil"
+	"github.com/redwookcreek/snake/snake"
+)
+
+// Bind keys to the actions, starting from a preset. The first entry
+// is the preset, then one entry per action
+type controls_scene struct {
+	menu     menu
+	bindings Bindings
+
+	// Action waiting for its key, -1 if none
+	binding Action
+
+	changed bool
+
+	// Why the last key pressed was not bound, e.g. a reserved key
+	message string
+}
+
+func new_controls_scene(bindings Bindings) *controls_scene {
+	return &controls_scene{menu{}, bindings, -1, false, ""}
+}
+
+func (c *controls_scene) Update(a *App) error {
+	if c.binding >= 0 {
+		// the next key pressed is bound, Escape cancels
+		if keys := inpututil.AppendJustPressedKeys(nil); len(keys) > 0 {
+			if keys[0] != ebiten.KeyEscape {
+				if err := c.bindings.bind(c.binding, keys[0]); err != nil {
+					c.message = err.Error()
+				} else {
+					c.changed = true
+				}
+			}
+			c.binding = -1
+		}
+		return nil
+	}
+
+	entries := int(ACTIONS) + 2
+	back_entry := entries - 1
+	chosen := c.menu.update(a, entries)
+	if chosen {
+		c.message = ""
+	}
+	back := chosen && c.menu.selected == back_entry
+	if chosen && c.menu.selected > 0 && c.menu.selected < back_entry {
+		c.binding = Action(c.menu.selected - 1)
+	}
+	for _, key := range inpututil.AppendJustPressedKeys(nil) {
+		if key == ebiten.KeyEscape {
+			back = true
+		}
+		if step := menu_step(a, key); step != 0 && c.menu.selected == 0 {
+			c.change_preset(step)
+		}
+	}
+	if back {
+		if c.changed {
+			a.bindings = c.bindings
+			config := a.Config
+			config.Bindings = c.bindings.config()
+			a.set_config(config, func(saved *snake.Config) {
+				saved.Bindings = config.Bindings
+			})
+		}
+		a.switch_to(new_title_scene(a))
+	}
+	return nil
+}
+
+// Bind the keys of the next preset, or of the previous one if step is -1
+func (c *controls_scene) change_preset(step int) {
+	name := c.bindings.preset()
+	i := slices.IndexFunc(PRESETS, func(p Preset) bool { return p.Name == name })
+	if i < 0 && step > 0 {
+		i = -1
+	} else if i < 0 {
+		i = 0
+	}
+	c.bindings = PRESETS[cycle(i, step, len(PRESETS))].Bindings
+	c.changed = true
+}
+
+func (c *controls_scene) Draw(screen *ebiten.Image) {
+	entries := []string{fmt.Sprintf("%-10s < %s >", "Preset", c.bindings.preset())}
+	for action, keys := range c.bindings {
+		value := key_names(keys)
+		if Action(action) == c.binding {
+			value = "press a key..."
+		}
+		entries = append(entries, fmt.Sprintf("%-10s %s", Action(action), value))
+	}
+	entries = append(entries, "Back")
+	draw_menu(screen, "Controls", entries, c.menu.selected)
+
+	hint := "Enter to bind a key, Left/Right for the presets, Esc to go back"
+	if c.binding >= 0 {
+		hint = fmt.Sprintf("Press the key for %s, Esc to cancel", c.binding)
+	} else if c.message != "" {
+		hint = c.message
+	}
+	draw_game_info(screen, screen.Bounds().Dx()/2-200, screen.Bounds().Dy()-40, hint)
+}
+
+// Returns the names of keys to show, e.g. "W/ArrowUp"
+func key_names(keys []ebiten.Key) string {
+	if len(keys) == 0 {
+		return "none"
+	}
+	names := []string{}
+	for _, key := range keys {
+		names = append(names, key.String())
+	}
+	return strings.Join(names, "/")
+}
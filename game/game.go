@@ -0,0 +1,429 @@
+package game
+
+import (
+	"fmt"
+	"image/color"
+	"log"
+	"time"
+
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/hajimehoshi/ebiten/v2/inpututil"
+	"github.com/hajimehoshi/ebiten/v2/vector"
+	"github.com/redwookcreek/snake/snake"
+)
+
+const (
+
+	// MARGIN between game board and screen boundary
+	MARGIN = 0
+
+	NORMAL_FONT_SIZE = 13
+
+	// Frames the snake is shown, then hidden, while respawning
+	RESPAWN_BLINK_FRAMES = 8
+)
+
+type Game struct {
+	SnakeState snake.SnakeState
+
+	// Wins and deaths of the games played since start
+	Stats snake.GameStats
+
+	// If not empty, the recording of every finished game is saved to this file
+	ReplayPath string
+
+	// If not nil, the snake is controlled by this instead of the keyboard
+	Controller snake.Controller
+
+	// How fast the snake moves
+	Speed snake.Speed
+
+	// Turns frames into snake ticks at the current speed
+	ticker snake.Ticker
+
+	// Number of frames the game has been played, not counting pauses
+	play_frames uint64
+
+	// Turns pressed, one is played every snake tick
+	input snake.InputQueue
+
+	// Recording of the game being played
+	replay *snake.Replay
+
+	// Not nil if the game is played back from a replay instead of the keyboard
+	playback *snake.Playback
+
+	// Not nil if playing a campaign
+	campaign *campaign_state
+
+	// Not nil if the high scores are kept, see EnableHighScores
+	high_scores *high_score_state
+
+	// Not nil if the game in progress is saved, see EnableSave
+	saving *save_state
+
+	// If not empty, Stats are saved to this file, see EnableStats
+	stats_path string
+}
+
+func CreateGame(height, width int, opts ...snake.SnakeOption) *Game {
+	ss := snake.CreateSnake(height, width, opts...)
+	g := &Game{
+		*ss,
+		snake.GameStats{},
+		// no replay file
+		"",
+		// played with the keyboard
+		nil,
+		snake.DEFAULT_SPEED,
+		snake.Ticker{},
+		0,
+		snake.InputQueue{},
+		// recording and playback
+		nil,
+		nil,
+		// no campaign
+		nil,
+		// no high scores
+		nil,
+		// not saved
+		nil,
+		// stats not kept
+		"",
+	}
+	g.replay = snake.NewReplay(&g.SnakeState)
+	return g
+}
+
+// Create a game that plays back a recorded game
+func CreatePlaybackGame(r *snake.Replay) *Game {
+	g := &Game{Speed: snake.DEFAULT_SPEED}
+	g.start_playback(r)
+	return g
+}
+
+func (g *Game) start_playback(r *snake.Replay) {
+	g.playback = snake.NewPlayback(r)
+	g.SnakeState = *g.playback.State
+	// let the playback drive the game's state
+	g.playback.State = &g.SnakeState
+	g.reset_clock()
+}
+
+func (g *Game) reset_clock() {
+	g.ticker.Reset()
+	g.play_frames = 0
+}
+
+func (g *Game) RestartGame() {
+	if g.playback != nil {
+		g.start_playback(g.playback.Replay)
+		return
+	}
+	if g.campaign != nil && g.campaign.finished {
+		g.restart_campaign()
+		return
+	}
+	if g.campaign.level_complete() {
+		// the level score is added when the next level starts
+		return
+	}
+	g.start_game(g.SnakeState.Level, 0)
+}
+
+// Start a new game on the level, keeping the board size and rules.
+// The game starts with the lives from the rules if lives is 0
+func (g *Game) start_game(level *snake.Level, lives int) {
+	ss := snake.CreateSnake(
+		g.SnakeState.Height,
+		g.SnakeState.Width,
+		snake.WithRules(g.SnakeState.Rules),
+		snake.WithLevel(level),
+		snake.WithLives(lives))
+	g.SnakeState = *ss
+	g.input.Clear()
+	g.reset_clock()
+	g.replay = snake.NewReplay(&g.SnakeState)
+}
+
+// Move the snake one tick with the keyboard or the replay
+func (g *Game) tick_snake() {
+	if g.playback != nil {
+		if err := g.playback.Step(); err != nil {
+			log.Fatal(err)
+		}
+		return
+	}
+	var dir int
+	if g.Controller != nil {
+		dir = g.Controller.NextDirection(g.SnakeState.View())
+	} else {
+		dir = g.input.Pop(g.SnakeState.Direction)
+	}
+	// Update direction
+	g.SnakeState.UpdateDirection(dir)
+	// move snake one tick
+	g.SnakeState.Tick()
+	g.replay.Record(dir, &g.SnakeState)
+}
+
+// Called once when a game ends
+func (g *Game) finish_game() {
+	if g.playback != nil {
+		return
+	}
+	if g.campaign != nil && g.campaign.finished {
+		g.Stats.RecordCampaign(g.campaign.Campaign)
+	} else {
+		g.Stats.Record(&g.SnakeState)
+	}
+	g.save_stats()
+	g.record_high_score()
+	g.remove_save()
+	if g.ReplayPath != "" {
+		if err := snake.SaveReplay(g.ReplayPath, g.replay); err != nil {
+			log.Printf("Failed to save replay: %v", err)
+		}
+	}
+}
+
+// Keep the stats of the games played in a file, the stats start
+// from the ones in the file. They start over if an error is returned
+func (g *Game) EnableStats(path string) error {
+	g.stats_path = path
+	stats, err := snake.LoadStats(path)
+	g.Stats = stats
+	return err
+}
+
+func (g *Game) save_stats() {
+	if g.stats_path == "" {
+		return
+	}
+	if err := snake.SaveStats(g.stats_path, g.Stats); err != nil {
+		log.Printf("Failed to save stats: %v", err)
+	}
+}
+
+// True if the snake can move
+func (g *Game) playing() bool {
+	// A playback stops at the end of the recording even if the game was not over
+	playback_done := g.playback != nil && g.playback.Done()
+	return !g.SnakeState.GameOver && !playback_done && !g.campaign.level_complete()
+}
+
+// Advance the game one snake tick
+func (g *Game) step() {
+	g.tick_snake()
+	if g.SnakeState.GameOver {
+		g.finish_game()
+	} else if g.campaign != nil && g.campaign.LevelComplete(&g.SnakeState) {
+		g.complete_level()
+	}
+}
+
+func (g *Game) Update(a *App) error {
+	// Queue the turns pressed
+	var keys []ebiten.Key
+	keys = inpututil.AppendJustPressedKeys(keys)
+	for _, action := range a.bindings.actions(keys) {
+		switch action {
+		case ACTION_UP, ACTION_DOWN, ACTION_LEFT, ACTION_RIGHT:
+			g.input.Push(ACTION_DIRECTIONS[action], g.SnakeState.Direction)
+		case ACTION_RESTART:
+			// Restart, or watch a replay again
+			g.RestartGame()
+		case ACTION_PAUSE:
+			if g.playing() {
+				a.switch_to(new_pause_scene(a, g))
+				return nil
+			}
+		}
+	}
+	if inpututil.IsKeyJustPressed(ebiten.KeyEscape) {
+		// Escape pauses too, and leaves a finished campaign
+		if g.playing() {
+			a.switch_to(new_pause_scene(a, g))
+		} else {
+			a.to_title()
+		}
+		return nil
+	}
+
+	// Pause when the window loses focus
+	if !ebiten.IsFocused() && g.playing() {
+		a.switch_to(new_pause_scene(a, g))
+		return nil
+	}
+	if g.campaign.level_complete() && !g.campaign.finished {
+		g.update_level_transition(keys)
+		return nil
+	}
+	if !g.playing() {
+		a.switch_to(&game_over_scene{g, key_names(a.bindings[ACTION_RESTART]), key_names(a.bindings[ACTION_HIGH_SCORES])})
+		return nil
+	}
+
+	g.play_frames += 1
+	ticks := g.ticker.Frame(ebiten.TPS(), g.tick_interval())
+	for i := 0; i < ticks && g.playing(); i++ {
+		g.step()
+	}
+	g.maybe_autosave()
+	return nil
+}
+
+// Time between two snake ticks, at the speed of the game
+// changed by the speed items eaten
+func (g *Game) tick_interval() time.Duration {
+	// speed up with the apples eaten, not the bonus points
+	interval := g.Speed.TickInterval(g.SnakeState.Breakdown.Apples)
+	return time.Duration(float64(interval) / g.SnakeState.SpeedFactor())
+}
+
+func draw_game_info(screen *ebiten.Image, x int, y int, msg string) {
+	draw_text(screen, x, y, NORMAL_FONT_SIZE, color.White, msg)
+}
+
+func (g *Game) Draw(screen *ebiten.Image) {
+	l := new_layout(screen.Bounds().Dx(), screen.Bounds().Dy(), g.SnakeState.Width, g.SnakeState.Height)
+
+	// Draw boarder, a board that wraps around has none
+	if !g.SnakeState.Rules.Wrap {
+		draw_boarder(screen, l)
+	}
+	if g.SnakeState.Level != nil {
+		draw_obstacles(screen, g.SnakeState.Level.Obstacles, l)
+	}
+
+	// Print game stat, score and ticks played
+	score_str := fmt.Sprintf("Score: %5d", g.SnakeState.Score)
+	tick_str := fmt.Sprintf("Time:  %5d", g.play_frames/uint64(ebiten.TPS()))
+	draw_game_info(screen, HUD_X, hud_y(0), score_str)
+	draw_game_info(screen, HUD_X+120, hud_y(0), tick_str)
+	if g.SnakeState.Rules.Lives > 1 {
+		lives_str := fmt.Sprintf("Lives: %d", g.SnakeState.Lives)
+		draw_game_info(screen, HUD_X+240, hud_y(0), lives_str)
+	}
+	if g.playback != nil {
+		draw_game_info(screen, HUD_X+340, hud_y(0), "Replay")
+	}
+	if g.SnakeState.Rules.TimedApples && g.SnakeState.Combo > 0 {
+		combo_str := fmt.Sprintf("Combo x%d", g.SnakeState.ComboMultiplier())
+		draw_game_info(screen, HUD_X+420, hud_y(0), combo_str)
+	}
+	if g.campaign != nil {
+		draw_campaign_info(screen, g.campaign, HUD_X, hud_y(1))
+	}
+	draw_effects_info(screen, &g.SnakeState, HUD_X, hud_y(2))
+
+	// Draw snake, blinking while it respawns
+	blink_off := g.SnakeState.Respawning > 0 && (g.play_frames/RESPAWN_BLINK_FRAMES)%2 == 1
+	if !blink_off {
+		tint := snake_color_scale(&g.SnakeState, g.play_frames)
+		for _, snake_part := range g.SnakeState.SnakeBody {
+			draw_snake_part(screen, snake_part, tint, l)
+		}
+	}
+
+	// Draw apple
+	if g.SnakeState.HasApple() {
+		draw_apple(screen, g.SnakeState.Apple, l)
+		if g.SnakeState.Rules.TimedApples {
+			draw_apple_countdown(screen, &g.SnakeState, l)
+		}
+	}
+	draw_items(screen, g.SnakeState.Items, g.play_frames, l)
+
+	if g.campaign.level_complete() {
+		draw_level_complete_screen(screen, g.campaign, g.SnakeState.Score)
+	}
+}
+
+// Dim the whole screen
+func draw_overlay(screen *ebiten.Image) {
+	vector.DrawFilledRect(
+		screen,
+		0, 0,
+		float32(screen.Bounds().Dx()), float32(screen.Bounds().Dy()),
+		OVERLAY_COLOR, false)
+}
+
+func draw_victory_screen(screen *ebiten.Image, stats snake.GameStats) {
+	draw_overlay(screen)
+	draw_game_info(screen, screen.Bounds().Dx()/2-30, screen.Bounds().Dy()/2, "You Win!")
+	draw_game_info(screen, screen.Bounds().Dx()/2-60, screen.Bounds().Dy()/2+20,
+		fmt.Sprintf("Won: %d  Died: %d", stats.Won, stats.Died))
+}
+
+// Draw a bar under the apple that shrinks as its countdown runs out,
+// from green to red
+func draw_apple_countdown(screen *ebiten.Image, ss *snake.SnakeState, l board_layout) {
+	left := float64(ss.AppleTicks) / float64(ss.AppleCountdown())
+	bar_color := color.RGBA{uint8(0xff * (1 - left)), uint8(0xff * left), 0, 0xff}
+	x, y := l.cell_pos(ss.Apple.X, ss.Apple.Y+1)
+	vector.DrawFilledRect(
+		screen,
+		float32(x), float32(y-3),
+		float32(left*l.cell), 3,
+		bar_color, false)
+}
+
+func draw_score_breakdown(screen *ebiten.Image, b snake.ScoreBreakdown, x, y int) {
+	draw_game_info(screen, x, y, fmt.Sprintf(
+		"Apples %d  Speed +%d  Combo +%d  Items +%d",
+		b.Apples, b.SpeedBonus, b.ComboBonus, b.Items))
+}
+
+// Draw the first and last rows and columns of the board
+func draw_boarder(screen *ebiten.Image, l board_layout) {
+	width := float32(l.cell) * float32(l.width)
+	height := float32(l.cell) * float32(l.height)
+	cell := float32(l.cell)
+	x, y := float32(l.x), float32(l.y)
+
+	// First and last row
+	vector.DrawFilledRect(screen, x, y, width, cell, BOARDER_COLOR, false)
+	vector.DrawFilledRect(screen, x, y+height-cell, width, cell, BOARDER_COLOR, false)
+
+	// First and last column
+	vector.DrawFilledRect(screen, x, y, cell, height, BOARDER_COLOR, false)
+	vector.DrawFilledRect(screen, x+width-cell, y, cell, height, BOARDER_COLOR, false)
+}
+
+// Draw the walls inside the boarder
+func draw_obstacles(screen *ebiten.Image, obstacles []snake.Point, l board_layout) {
+	for _, p := range obstacles {
+		x, y := l.cell_pos(p.X, p.Y)
+		vector.DrawFilledRect(
+			screen,
+			float32(x), float32(y),
+			float32(l.cell), float32(l.cell),
+			BOARDER_COLOR, false)
+	}
+}
+
+func draw_snake_part(screen *ebiten.Image, snake_part snake.SnakePart, tint ebiten.ColorScale, l board_layout) {
+	body_part_img, ok := BODY_PART_TO_IMG_MAP[snake_part.PartType]
+	if !ok {
+		log.Fatalf("Unknow body type %v", snake_part)
+	}
+	op := cell_img_option(snake_part.Cord.X, snake_part.Cord.Y, l)
+	op.ColorScale.ScaleWithColorScale(tint)
+	screen.DrawImage(body_part_img, op)
+}
+
+func draw_apple(screen *ebiten.Image, apple_cord snake.Point, l board_layout) {
+	screen.DrawImage(APPLE_IMG, cell_img_option(apple_cord.X, apple_cord.Y, l))
+}
+
+// Returns the image option for drawing a cell
+// The option will contain tranlate x, y and scale
+func cell_img_option(col, row int, l board_layout) *ebiten.DrawImageOptions {
+	op := &ebiten.DrawImageOptions{}
+	// Scale the image so that it fits in one cell
+	op.GeoM.Scale(CellScale(l.cell), CellScale(l.cell))
+	// Move the image to dst col and row
+	op.GeoM.Translate(l.cell_pos(col, row))
+	return op
+}
@@ -0,0 +1,23 @@
+package game
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/redwookcreek/snake/snake"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestStatsKeptBetweenGames(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "stats.json")
+	g := CreateGame(10, 10)
+	assert.NoError(t, g.EnableStats(path))
+	g.SnakeState.GameOver = true
+	g.SnakeState.Score = 4
+	g.finish_game()
+
+	// The next game, e.g. after a restart of the app, goes on from them
+	next := CreateGame(10, 10)
+	assert.NoError(t, next.EnableStats(path))
+	assert.Equal(t, snake.GameStats{Played: 1, Died: 1, BestScore: 4}, next.Stats)
+}
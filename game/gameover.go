@@ -0,0 +1,72 @@
+package game
+
+import (
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/hajimehoshi/ebiten/v2/inpututil"
+)
+
+// Shown over a game once it is over, asks for the name of a new high score
+type game_over_scene struct {
+	game *Game
+
+	// Keys to play again and to show the high scores, shown in the hint
+	restart_keys string
+	scores_keys  string
+}
+
+func (s *game_over_scene) Update(a *App) error {
+	g := s.game
+	if g.high_scores.entering_name() {
+		if g.update_name_entry() {
+			hs := g.high_scores
+			key, _ := g.high_score()
+			a.switch_to(new_high_scores_scene(hs.table, key, hs.rank, s))
+		}
+		return nil
+	}
+	for _, key := range inpututil.AppendJustPressedKeys(nil) {
+		switch {
+		case a.bindings.is(ACTION_RESTART, key):
+			// play again, or watch a replay again
+			g.RestartGame()
+			a.switch_to(g)
+			return nil
+		case a.bindings.is(ACTION_HIGH_SCORES, key):
+			if hs := g.high_scores; hs != nil {
+				key, _ := g.high_score()
+				a.switch_to(new_high_scores_scene(hs.table, key, -1, s))
+				return nil
+			}
+		case key == ebiten.KeyEscape || key == ebiten.KeyQ:
+			a.to_title()
+			return nil
+		}
+	}
+	return nil
+}
+
+func (s *game_over_scene) Draw(screen *ebiten.Image) {
+	g := s.game
+	g.Draw(screen)
+	x, y := screen.Bounds().Dx()/2, screen.Bounds().Dy()/2
+	switch {
+	case g.campaign != nil && g.campaign.finished:
+		// the game draws the campaign complete screen
+	case g.SnakeState.GameWon:
+		// The snake covers the whole board, dim it so the message is readable
+		draw_victory_screen(screen, g.Stats)
+	case g.SnakeState.GameOver:
+		draw_game_info(screen, x-30, y, "Game Over")
+		draw_score_breakdown(screen, g.SnakeState.Breakdown, x-100, y+20)
+	default:
+		draw_game_info(screen, x-50, y, "End of the replay")
+	}
+	hint := s.restart_keys + ": play again  Esc: menu"
+	if g.high_scores != nil {
+		hint = s.restart_keys + ": play again  " + s.scores_keys + ": high scores  Esc: menu"
+	}
+	draw_game_info(screen, x-100, y+60, hint)
+	if g.high_scores.entering_name() {
+		draw_name_entry(screen, g.high_scores)
+	}
+}
@@ -0,0 +1,186 @@
+package game
+
+import (
+	"fmt"
+	"log"
+	"slices"
+	"time"
+	"unicode"
+
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/hajimehoshi/ebiten/v2/inpututil"
+	"github.com/redwookcreek/snake/snake"
+)
+
+// Name of a high score if none is typed
+const DEFAULT_PLAYER_NAME = "Player"
+
+// High scores of the games played with the keyboard
+type high_score_state struct {
+	table snake.HighScores
+
+	// File the table is saved to
+	path string
+
+	// True while the player types a name for a new high score
+	entering bool
+	name     []rune
+	score    int
+
+	// Rank of the score added last, -1 if none
+	rank int
+}
+
+// Keep the high scores of the games played in a file. The game works
+// without the scores that could not be loaded, if an error is returned
+func (g *Game) EnableHighScores(path string) error {
+	table, err := snake.LoadHighScores(path)
+	g.high_scores = &high_score_state{table: table, path: path, rank: -1}
+	return err
+}
+
+// Called when a game ends, a score that makes it to the table asks for a name
+func (g *Game) record_high_score() {
+	hs := g.high_scores
+	if hs == nil || g.Controller != nil {
+		// bots do not get on the table
+		return
+	}
+	hs.rank = -1
+	if key, score := g.high_score(); hs.table.Qualifies(key, score) {
+		hs.entering = true
+		hs.score = score
+	}
+}
+
+// Returns the board and the score of the game on the high score table.
+// A campaign counts the total score of its levels
+func (g *Game) high_score() (string, int) {
+	if c := g.campaign; c != nil {
+		score := c.TotalScore
+		if !c.finished {
+			// the level played is not added to the total yet
+			score += g.SnakeState.Score
+		}
+		return snake.CampaignHighScoreKey(g.SnakeState.Rules), score
+	}
+	return snake.HighScoreKey(&g.SnakeState), g.SnakeState.Score
+}
+
+func (hs *high_score_state) entering_name() bool {
+	return hs != nil && hs.entering
+}
+
+// Type the name of the new high score, Enter saves it and Escape skips it.
+// Returns true once the score is saved
+func (g *Game) update_name_entry() bool {
+	hs := g.high_scores
+	for _, r := range ebiten.AppendInputChars(nil) {
+		if unicode.IsPrint(r) && len(hs.name) < snake.MAX_NAME_LENGTH {
+			hs.name = append(hs.name, r)
+		}
+	}
+	switch {
+	case inpututil.IsKeyJustPressed(ebiten.KeyBackspace) && len(hs.name) > 0:
+		hs.name = hs.name[:len(hs.name)-1]
+	case inpututil.IsKeyJustPressed(ebiten.KeyEnter):
+		g.save_high_score()
+		return true
+	case inpututil.IsKeyJustPressed(ebiten.KeyEscape):
+		hs.entering = false
+	}
+	return false
+}
+
+func (g *Game) save_high_score() {
+	hs := g.high_scores
+	hs.entering = false
+	name := string(hs.name)
+	if name == "" {
+		name = DEFAULT_PLAYER_NAME
+	}
+	// the name is kept for the next high score
+	score := snake.HighScore{Name: name, Score: hs.score, Date: time.Now()}
+	key, _ := g.high_score()
+	hs.rank = hs.table.Add(key, score)
+	if err := snake.SaveHighScores(hs.path, hs.table); err != nil {
+		log.Printf("Failed to save high scores: %v", err)
+	}
+}
+
+func draw_name_entry(screen *ebiten.Image, hs *high_score_state) {
+	draw_overlay(screen)
+	x, y := screen.Bounds().Dx()/2-100, screen.Bounds().Dy()/2-20
+	draw_game_info(screen, x, y, fmt.Sprintf("New high score: %d", hs.score))
+	draw_game_info(screen, x, y+20, "Name: "+string(hs.name)+"_")
+	draw_game_info(screen, x, y+40, "Enter to save, Esc to skip")
+}
+
+// Shows the best scores of every board, one board at a time
+type high_scores_scene struct {
+	table snake.HighScores
+
+	// Boards with scores, and the one the scene was opened on
+	boards  []string
+	current int
+
+	// Board and rank of the score added last, rank is -1 if none
+	added string
+	rank  int
+
+	// Scene shown behind the table, and when leaving it
+	back Scene
+}
+
+func new_high_scores_scene(table snake.HighScores, board string, rank int, back Scene) *high_scores_scene {
+	boards := []string{board}
+	for key := range table.Boards {
+		if key != board {
+			boards = append(boards, key)
+		}
+	}
+	slices.Sort(boards)
+	return &high_scores_scene{table, boards, slices.Index(boards, board), board, rank, back}
+}
+
+// Left and right go through the boards, Escape goes back
+func (s *high_scores_scene) Update(a *App) error {
+	for _, key := range inpututil.AppendJustPressedKeys(nil) {
+		if step := menu_step(a, key); step != 0 {
+			s.current = cycle(s.current, step, len(s.boards))
+		}
+		switch key {
+		case ebiten.KeyEscape, ebiten.KeyEnter:
+			a.switch_to(s.back)
+		}
+	}
+	return nil
+}
+
+func (s *high_scores_scene) Draw(screen *ebiten.Image) {
+	s.back.Draw(screen)
+	board := s.boards[s.current]
+	rank := -1
+	if board == s.added {
+		rank = s.rank
+	}
+	draw_high_scores(screen, s.table.Top(board), board, rank)
+}
+
+func draw_high_scores(screen *ebiten.Image, top []snake.HighScore, board string, rank int) {
+	draw_overlay(screen)
+	x, y := screen.Bounds().Dx()/2-120, screen.Bounds().Dy()/2-140
+	draw_game_info(screen, x, y, "High scores  "+board)
+	if len(top) == 0 {
+		draw_game_info(screen, x, y+30, "No scores yet")
+	}
+	for i, s := range top {
+		mark := " "
+		if i == rank {
+			mark = ">"
+		}
+		line := fmt.Sprintf("%s%2d. %-16s %5d  %s", mark, i+1, s.Name, s.Score, s.Date.Format(time.DateOnly))
+		draw_game_info(screen, x, y+30+i*20, line)
+	}
+	draw_game_info(screen, x, y+30+(snake.MAX_HIGH_SCORES+1)*20, "Left/Right for the other boards, Esc to go back")
+}
@@ -0,0 +1,190 @@
+package game
+
+import (
+	"fmt"
+	"slices"
+
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/redwookcreek/snake/snake"
+)
+
+// Something the player does with a key, whatever key it is bound to
+type Action int
+
+const (
+	ACTION_UP Action = iota
+	ACTION_DOWN
+	ACTION_LEFT
+	ACTION_RIGHT
+	ACTION_RESTART
+	ACTION_PAUSE
+	// Show the high scores once a game is over
+	ACTION_HIGH_SCORES
+
+	// Number of actions
+	ACTIONS
+)
+
+// Names of the actions in the config file
+var ACTION_NAMES = [ACTIONS]string{"up", "down", "left", "right", "restart", "pause", "scores"}
+
+// Direction of the snake for the move actions
+var ACTION_DIRECTIONS = map[Action]int{
+	ACTION_UP:    snake.UP,
+	ACTION_DOWN:  snake.DOWN,
+	ACTION_LEFT:  snake.LEFT,
+	ACTION_RIGHT: snake.RIGHT,
+}
+
+func (a Action) String() string {
+	return ACTION_NAMES[a]
+}
+
+// Keys bound to each action
+type Bindings [ACTIONS][]ebiten.Key
+
+// Bindings to start from
+type Preset struct {
+	Name     string
+	Bindings Bindings
+}
+
+var PRESETS = []Preset{
+	{"arrows", Bindings{
+		{ebiten.KeyArrowUp}, {ebiten.KeyArrowDown}, {ebiten.KeyArrowLeft}, {ebiten.KeyArrowRight},
+		{ebiten.KeyR}, {ebiten.KeyP}, {ebiten.KeyH},
+	}},
+	{"wasd", Bindings{
+		{ebiten.KeyW}, {ebiten.KeyS}, {ebiten.KeyA}, {ebiten.KeyD},
+		{ebiten.KeyR}, {ebiten.KeyP}, {ebiten.KeyH},
+	}},
+	// H moves left, the high scores are on T for table
+	{"vim", Bindings{
+		{ebiten.KeyK}, {ebiten.KeyJ}, {ebiten.KeyH}, {ebiten.KeyL},
+		{ebiten.KeyR}, {ebiten.KeyP}, {ebiten.KeyT},
+	}},
+}
+
+// Bindings if the config file has none
+var DEFAULT_BINDINGS = PRESETS[0].Bindings
+
+// Name of a preset for bindings that are not one
+const CUSTOM_PRESET = "custom"
+
+// Keys the menus and the pause screen use, they cannot be bound to an action.
+// H is not one of them, the vim preset moves left with it
+var RESERVED_KEYS = []ebiten.Key{ebiten.KeyEnter, ebiten.KeySpace, ebiten.KeyEscape, ebiten.KeyN, ebiten.KeyQ}
+
+// Returns the bindings of the config file, by action name then key name.
+// The actions not in the file keep their default keys, a key cannot
+// be bound to two actions
+func ParseBindings(config map[string][]string) (Bindings, error) {
+	b := DEFAULT_BINDINGS
+	for name, key_names := range config {
+		action := Action(slices.Index(ACTION_NAMES[:], name))
+		if action < 0 {
+			return b, fmt.Errorf("unknown action %q, should be one of %v", name, ACTION_NAMES)
+		}
+		keys := []ebiten.Key{}
+		for _, key_name := range key_names {
+			var key ebiten.Key
+			if err := key.UnmarshalText([]byte(key_name)); err != nil {
+				return b, fmt.Errorf("unknown key %q for %s", key_name, action)
+			}
+			if slices.Contains(RESERVED_KEYS, key) {
+				return b, fmt.Errorf("key %s for %s is reserved", key, action)
+			}
+			if err := check_versus_key(action, key); err != nil {
+				return b, fmt.Errorf("key %w", err)
+			}
+			keys = append(keys, key)
+		}
+		b[action] = keys
+	}
+	for action := range b {
+		for other := action + 1; other < len(b); other++ {
+			for _, key := range b[action] {
+				if b.is(Action(other), key) {
+					return b, fmt.Errorf("key %s is bound to both %s and %s", key, Action(action), Action(other))
+				}
+			}
+		}
+	}
+	return b, nil
+}
+
+// Returns the bindings to write to the config file
+func (b *Bindings) config() map[string][]string {
+	config := map[string][]string{}
+	for action, keys := range b {
+		names := []string{}
+		for _, key := range keys {
+			names = append(names, key.String())
+		}
+		config[ACTION_NAMES[action]] = names
+	}
+	return config
+}
+
+// True if the key is bound to the action
+func (b *Bindings) is(action Action, key ebiten.Key) bool {
+	return slices.Contains(b[action], key)
+}
+
+// Returns the actions of the keys pressed, in the order of the keys
+func (b *Bindings) actions(keys []ebiten.Key) []Action {
+	actions := []Action{}
+	for _, key := range keys {
+		for action := range b {
+			if b.is(Action(action), key) {
+				actions = append(actions, Action(action))
+			}
+		}
+	}
+	return actions
+}
+
+// Returns an error if the key steers a snake in versus games and the
+// action is not a turn, the action could not be done in versus games
+func check_versus_key(action Action, key ebiten.Key) error {
+	if _, turn := ACTION_DIRECTIONS[action]; !turn && versus_key(key) {
+		return fmt.Errorf("%s for %s steers a snake in versus games", key, action)
+	}
+	return nil
+}
+
+// Bind a key to an action instead of its keys, the key does not do the
+// other actions anymore. Reserved keys are refused, and so are the keys
+// of the versus players for the actions that are not turns
+func (b *Bindings) bind(action Action, key ebiten.Key) error {
+	if slices.Contains(RESERVED_KEYS, key) {
+		return fmt.Errorf("%s is reserved", key)
+	}
+	if err := check_versus_key(action, key); err != nil {
+		return err
+	}
+	for other := range b {
+		b[other] = slices.DeleteFunc(slices.Clone(b[other]), func(k ebiten.Key) bool { return k == key })
+	}
+	b[action] = []ebiten.Key{key}
+	return nil
+}
+
+// Returns the name of the preset with the bindings, CUSTOM_PRESET if none
+func (b *Bindings) preset() string {
+	for _, p := range PRESETS {
+		if p.Bindings.equal(b) {
+			return p.Name
+		}
+	}
+	return CUSTOM_PRESET
+}
+
+func (b *Bindings) equal(other *Bindings) bool {
+	for action := range b {
+		if !slices.Equal(b[action], other[action]) {
+			return false
+		}
+	}
+	return true
+}
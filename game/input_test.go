@@ -0,0 +1,89 @@
+package game
+
+import (
+	"testing"
+
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/redwookcreek/snake/snake"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseBindings(t *testing.T) {
+	b, err := ParseBindings(nil)
+	assert.NoError(t, err)
+	assert.Equal(t, DEFAULT_BINDINGS, b)
+
+	// Actions not in the config keep their keys
+	b, err = ParseBindings(map[string][]string{"up": {"W", "ArrowUp"}, "pause": {"Tab"}})
+	assert.NoError(t, err)
+	assert.Equal(t, []ebiten.Key{ebiten.KeyW, ebiten.KeyArrowUp}, b[ACTION_UP])
+	assert.Equal(t, []ebiten.Key{ebiten.KeyTab}, b[ACTION_PAUSE])
+	assert.Equal(t, DEFAULT_BINDINGS[ACTION_DOWN], b[ACTION_DOWN])
+
+	_, err = ParseBindings(map[string][]string{"jump": {"Space"}})
+	assert.ErrorContains(t, err, `unknown action "jump"`)
+	_, err = ParseBindings(map[string][]string{"up": {"Nope"}})
+	assert.ErrorContains(t, err, `unknown key "Nope" for up`)
+	_, err = ParseBindings(map[string][]string{"pause": {"Q"}})
+	assert.ErrorContains(t, err, "key Q for pause is reserved")
+	_, err = ParseBindings(map[string][]string{"pause": {"W"}})
+	assert.ErrorContains(t, err, "key W for pause steers a snake in versus games")
+	_, err = ParseBindings(map[string][]string{"up": {"W"}, "down": {"W"}})
+	assert.ErrorContains(t, err, "key W is bound to both up and down")
+	// The default keys of the actions not in the config count too
+	_, err = ParseBindings(map[string][]string{"restart": {"H"}})
+	assert.ErrorContains(t, err, "key H is bound to both restart and scores")
+	_, err = ParseBindings(map[string][]string{"restart": {"ArrowLeft"}})
+	assert.ErrorContains(t, err, "key ArrowLeft is bound to both left and restart")
+}
+
+func TestBindingsConfigRoundTrip(t *testing.T) {
+	for _, p := range PRESETS {
+		b, err := ParseBindings(p.Bindings.config())
+		assert.NoError(t, err)
+		assert.Equal(t, p.Bindings, b)
+		assert.Equal(t, p.Name, b.preset())
+	}
+}
+
+func TestBindingsActions(t *testing.T) {
+	b := PRESETS[2].Bindings
+	keys := []ebiten.Key{ebiten.KeyJ, ebiten.KeyArrowUp, ebiten.KeyP, ebiten.KeyH, ebiten.KeyT}
+	assert.Equal(t, []Action{ACTION_DOWN, ACTION_PAUSE, ACTION_LEFT, ACTION_HIGH_SCORES}, b.actions(keys))
+}
+
+func TestBind(t *testing.T) {
+	b := DEFAULT_BINDINGS
+	assert.NoError(t, b.bind(ACTION_UP, ebiten.KeyP))
+	assert.Equal(t, []ebiten.Key{ebiten.KeyP}, b[ACTION_UP])
+	// P does not pause anymore
+	assert.Empty(t, b[ACTION_PAUSE])
+	assert.Equal(t, CUSTOM_PRESET, b.preset())
+	// The preset is not changed
+	assert.Equal(t, []ebiten.Key{ebiten.KeyP}, DEFAULT_BINDINGS[ACTION_PAUSE])
+}
+
+func TestBindReserved(t *testing.T) {
+	b := DEFAULT_BINDINGS
+	for _, key := range RESERVED_KEYS {
+		assert.Error(t, b.bind(ACTION_LEFT, key))
+	}
+	assert.Equal(t, DEFAULT_BINDINGS, b)
+}
+
+func TestBindVersusKeys(t *testing.T) {
+	b := DEFAULT_BINDINGS
+	// The keys of the versus players cannot restart or pause
+	assert.Error(t, b.bind(ACTION_RESTART, ebiten.KeyArrowUp))
+	assert.Error(t, b.bind(ACTION_PAUSE, ebiten.KeyD))
+	assert.Equal(t, DEFAULT_BINDINGS, b)
+	// but they can turn
+	assert.NoError(t, b.bind(ACTION_UP, ebiten.KeyW))
+}
+
+func TestPauseSceneKeys(t *testing.T) {
+	a := NewApp(snake.DEFAULT_CONFIG)
+	assert.NoError(t, a.bindings.bind(ACTION_PAUSE, ebiten.KeyTab))
+	assert.Equal(t, "Tab/Escape", new_pause_scene(a, nil).resume_keys)
+	assert.Equal(t, []ebiten.Key{ebiten.KeyTab}, a.bindings[ACTION_PAUSE])
+}
@@ -0,0 +1,76 @@
+package game
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/hajimehoshi/ebiten/v2/vector"
+	"github.com/redwookcreek/snake/snake"
+)
+
+const (
+	// Items about to disappear blink in the last ticks
+	ITEM_BLINK_TICKS = 10
+
+	ITEM_BLINK_FRAMES = 6
+)
+
+func draw_items(screen *ebiten.Image, items []snake.Item, frames uint64, l board_layout) {
+	for _, item := range items {
+		if item.TicksLeft <= ITEM_BLINK_TICKS && (frames/ITEM_BLINK_FRAMES)%2 == 1 {
+			continue
+		}
+		draw_item(screen, item, l)
+	}
+}
+
+func draw_item(screen *ebiten.Image, item snake.Item, l board_layout) {
+	if item.Kind == snake.ITEM_GOLDEN_APPLE {
+		op := cell_img_option(item.Cord.X, item.Cord.Y, l)
+		op.ColorScale.ScaleWithColor(ITEM_COLORS[item.Kind])
+		screen.DrawImage(APPLE_IMG, op)
+		return
+	}
+	x, y := l.cell_pos(item.Cord.X, item.Cord.Y)
+	cx, cy := x+l.cell/2, y+l.cell/2
+	r := l.cell * 0.35
+	vector.DrawFilledCircle(screen, float32(cx), float32(cy), float32(r), ITEM_COLORS[item.Kind], true)
+	if item.Kind == snake.ITEM_INVINCIBILITY {
+		// a ring tells it from the pill
+		vector.StrokeCircle(screen, float32(cx), float32(cy), float32(r*1.3), 2, ITEM_COLORS[item.Kind], true)
+	}
+}
+
+// Tint of the snake while it is invincible, blinking near the end
+func snake_color_scale(ss *snake.SnakeState, frames uint64) ebiten.ColorScale {
+	var cs ebiten.ColorScale
+	if !ss.Invincible() {
+		return cs
+	}
+	if ss.InvincibleTicks <= ITEM_BLINK_TICKS && (frames/ITEM_BLINK_FRAMES)%2 == 1 {
+		return cs
+	}
+	cs.ScaleWithColor(ITEM_COLORS[snake.ITEM_INVINCIBILITY])
+	return cs
+}
+
+// Show the effects of the items eaten with the ticks they have left
+func draw_effects_info(screen *ebiten.Image, ss *snake.SnakeState, x, y int) {
+	effects := []string{}
+	for _, e := range []struct {
+		kind  snake.ItemKind
+		ticks int
+	}{
+		{snake.ITEM_SPEED_UP, ss.SpeedUpTicks},
+		{snake.ITEM_SLOW_DOWN, ss.SlowDownTicks},
+		{snake.ITEM_INVINCIBILITY, ss.InvincibleTicks},
+	} {
+		if e.ticks > 0 {
+			effects = append(effects, fmt.Sprintf("%s %d", e.kind, e.ticks))
+		}
+	}
+	if len(effects) > 0 {
+		draw_game_info(screen, x, y, strings.Join(effects, "  "))
+	}
+}
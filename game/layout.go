@@ -0,0 +1,53 @@
+package game
+
+import "math"
+
+const (
+	// Height of a line of info in the HUD
+	HUD_LINE_HEIGHT = 20
+
+	// The HUD above the board has room for three lines of info
+	HUD_HEIGHT = 3*HUD_LINE_HEIGHT + 10
+
+	// Left of the HUD info
+	HUD_X = MARGIN + 10
+)
+
+// Where the board is drawn on the screen. Cells are square and as big as
+// fits below the HUD, the board is centered in the space left and the
+// rest of the screen stays empty
+type board_layout struct {
+	// Top left corner of the board, in pixels
+	x, y float64
+
+	// Side of a cell, in pixels
+	cell float64
+
+	// Size of the board, in cells
+	width, height int
+}
+
+func new_layout(screen_width, screen_height, board_width, board_height int) board_layout {
+	area_width := float64(screen_width - 2*MARGIN)
+	area_height := float64(screen_height - HUD_HEIGHT - 2*MARGIN)
+	// whole pixels so that the cells line up
+	cell := math.Floor(min(area_width/float64(board_width), area_height/float64(board_height)))
+	cell = max(cell, 1)
+	return board_layout{
+		MARGIN + math.Floor((area_width-cell*float64(board_width))/2),
+		MARGIN + HUD_HEIGHT + math.Floor((area_height-cell*float64(board_height))/2),
+		cell,
+		board_width,
+		board_height,
+	}
+}
+
+// Returns the top left corner of a cell
+func (l board_layout) cell_pos(col, row int) (float64, float64) {
+	return l.x + float64(col)*l.cell, l.y + float64(row)*l.cell
+}
+
+// Returns the top of a line of the HUD
+func hud_y(line int) int {
+	return MARGIN + 5 + line*HUD_LINE_HEIGHT
+}
@@ -0,0 +1,54 @@
+package game
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLayoutSquareBoard(t *testing.T) {
+	l := new_layout(640, 640, 20, 20)
+	// 570 pixels left below the HUD, for 20 rows
+	assert.Equal(t, board_layout{40, HUD_HEIGHT + 5, 28, 20, 20}, l)
+
+	x, y := l.cell_pos(2, 3)
+	assert.Equal(t, 40+2*28.0, x)
+	assert.Equal(t, HUD_HEIGHT+5+3*28.0, y)
+}
+
+func TestLayoutWideBoard(t *testing.T) {
+	// As wide as the screen, centered vertically below the HUD
+	l := new_layout(640, 640, 40, 10)
+	assert.Equal(t, 16.0, l.cell)
+	assert.Equal(t, 0.0, l.x)
+	assert.Equal(t, float64(HUD_HEIGHT+(640-HUD_HEIGHT-160)/2), l.y)
+}
+
+func TestLayoutTallBoard(t *testing.T) {
+	// As tall as the space below the HUD, centered horizontally
+	l := new_layout(800, 600, 10, 40)
+	assert.Equal(t, 13.0, l.cell)
+	assert.Equal(t, float64((800-130)/2), l.x)
+	assert.Equal(t, float64(HUD_HEIGHT+5), l.y)
+}
+
+func TestLayoutFitsScreen(t *testing.T) {
+	for _, screen := range [][2]int{{640, 640}, {1920, 1080}, {300, 900}, {333, 517}} {
+		for _, board := range [][2]int{{3, 3}, {20, 20}, {50, 10}, {7, 31}} {
+			l := new_layout(screen[0], screen[1], board[0], board[1])
+			right, bottom := l.cell_pos(board[0], board[1])
+			assert.GreaterOrEqual(t, l.x, 0.0)
+			assert.GreaterOrEqual(t, l.y, float64(HUD_HEIGHT))
+			assert.LessOrEqual(t, right, float64(screen[0]))
+			assert.LessOrEqual(t, bottom, float64(screen[1]))
+			// Centered, at most a pixel off
+			assert.InDelta(t, l.x, float64(screen[0])-right, 1)
+			assert.InDelta(t, l.y-HUD_HEIGHT, float64(screen[1])-bottom, 1)
+		}
+	}
+}
+
+func TestLayoutTinyScreen(t *testing.T) {
+	l := new_layout(50, 50, 20, 20)
+	assert.Equal(t, 1.0, l.cell)
+}
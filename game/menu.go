@@ -0,0 +1,329 @@
+package game
+
+import (
+	"errors"
+	"fmt"
+	"image/color"
+	"log"
+	"os"
+	"slices"
+
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/hajimehoshi/ebiten/v2/inpututil"
+	"github.com/hajimehoshi/ebiten/v2/text/v2"
+	"github.com/redwookcreek/snake/snake"
+)
+
+const (
+	TITLE_FONT_SIZE = 40
+
+	// Space between the entries of a menu
+	MENU_LINE_HEIGHT = 24
+)
+
+// Entries of the title menu
+const (
+	TITLE_PLAY = iota
+	TITLE_CAMPAIGN
+	TITLE_NEW_CAMPAIGN
+	TITLE_VERSUS
+	TITLE_OPTIONS
+	TITLE_CONTROLS
+	TITLE_HIGH_SCORES
+	TITLE_QUIT
+)
+
+var TITLE_ENTRIES = []string{"Play", "Campaign", "New campaign", "Versus", "Options", "Controls", "High scores", "Quit"}
+
+// Board sizes to choose from in the options, width x height
+var BOARD_SIZES = [][2]int{{10, 10}, {15, 15}, {20, 20}, {30, 20}, {40, 25}}
+
+// Most lives to choose from in the options
+const MAX_MENU_LIVES = 5
+
+// A list of entries, one of them selected
+type menu struct {
+	selected int
+}
+
+// Move the selection with the arrow keys or the keys bound to up and down.
+// Returns true if the selected entry was chosen with Enter or Space
+func (m *menu) update(a *App, entries int) bool {
+	for _, key := range inpututil.AppendJustPressedKeys(nil) {
+		switch {
+		case key == ebiten.KeyArrowUp || a.bindings.is(ACTION_UP, key):
+			m.selected = cycle(m.selected, -1, entries)
+		case key == ebiten.KeyArrowDown || a.bindings.is(ACTION_DOWN, key):
+			m.selected = cycle(m.selected, 1, entries)
+		case key == ebiten.KeyEnter || key == ebiten.KeySpace:
+			return true
+		}
+	}
+	return false
+}
+
+// Returns -1 or 1 for the arrow keys or the keys bound to left and right, 0 for other keys
+func menu_step(a *App, key ebiten.Key) int {
+	switch {
+	case key == ebiten.KeyArrowLeft || a.bindings.is(ACTION_LEFT, key):
+		return -1
+	case key == ebiten.KeyArrowRight || a.bindings.is(ACTION_RIGHT, key):
+		return 1
+	}
+	return 0
+}
+
+// Returns the index step entries away from i, going around n entries
+func cycle(i, step, n int) int {
+	return ((i+step)%n + n) % n
+}
+
+// Draw the entries of a menu below a title, the selected one marked
+func draw_menu(screen *ebiten.Image, title string, entries []string, selected int) {
+	x, y := screen.Bounds().Dx()/2-100, screen.Bounds().Dy()/2-len(entries)*MENU_LINE_HEIGHT/2
+	draw_text(screen, x, y-2*TITLE_FONT_SIZE, TITLE_FONT_SIZE, color.White, title)
+	for i, entry := range entries {
+		entry_color := color.Color(color.Gray{0xa0})
+		if i == selected {
+			entry = "> " + entry
+			entry_color = color.White
+		}
+		draw_text(screen, x, y+i*MENU_LINE_HEIGHT, NORMAL_FONT_SIZE, entry_color, entry)
+	}
+}
+
+func draw_text(screen *ebiten.Image, x, y int, size float64, c color.Color, msg string) {
+	op := &text.DrawOptions{}
+	op.GeoM.Translate(float64(x), float64(y))
+	op.ColorScale.ScaleWithColor(c)
+	text.Draw(screen, msg, &text.GoTextFace{
+		Source: M_PLUS_FACE_SCOURCE,
+		Size:   size,
+	}, op)
+}
+
+// First scene, starts the games and leads to the other scenes
+type title_scene struct {
+	menu menu
+
+	// Board and rules of the games started, e.g. "20x20 wrap"
+	board string
+	speed string
+
+	// Why the selected entry could not start, e.g. Versus with lives
+	message string
+}
+
+func new_title_scene(a *App) *title_scene {
+	return &title_scene{menu{}, a.board_key(), a.Config.Speed, ""}
+}
+
+func (t *title_scene) Update(a *App) error {
+	if !t.menu.update(a, len(TITLE_ENTRIES)) {
+		return nil
+	}
+	t.message = ""
+	switch t.menu.selected {
+	case TITLE_PLAY:
+		if a.Level != nil {
+			// Wrap may have been turned on in the options
+			if err := snake.CheckLevelRules(a.Config.Rules()); err != nil {
+				t.message = err.Error()
+				return nil
+			}
+		}
+		a.Play(a.NewGame())
+	case TITLE_CAMPAIGN, TITLE_NEW_CAMPAIGN:
+		if err := a.PlayCampaign(t.menu.selected == TITLE_NEW_CAMPAIGN); err != nil {
+			t.message = err.Error()
+		}
+	case TITLE_VERSUS:
+		if err := a.PlayVersus(); err != nil {
+			t.message = err.Error()
+		}
+	case TITLE_OPTIONS:
+		a.switch_to(new_options_scene(a.Config))
+	case TITLE_CONTROLS:
+		a.switch_to(new_controls_scene(a.bindings))
+	case TITLE_HIGH_SCORES:
+		table := snake.HighScores{}
+		if a.HighScorePath != "" {
+			var err error
+			if table, err = snake.LoadHighScores(a.HighScorePath); err != nil {
+				log.Print(err)
+			}
+		}
+		a.switch_to(new_high_scores_scene(table, t.board, -1, t))
+	case TITLE_QUIT:
+		return ebiten.Termination
+	}
+	return nil
+}
+
+func (t *title_scene) Draw(screen *ebiten.Image) {
+	draw_menu(screen, "Snake", TITLE_ENTRIES, t.menu.selected)
+	x, y := screen.Bounds().Dx()/2-100, screen.Bounds().Dy()-60
+	if t.message != "" {
+		draw_game_info(screen, x, y-20, t.message)
+	}
+	draw_game_info(screen, x, y, fmt.Sprintf("%s  %s", t.board, t.speed))
+	draw_game_info(screen, x, y+20, "Up/Down to choose, Enter to select")
+}
+
+// Returns the board and rules of the games started from the menu,
+// as the high scores know them
+func (a *App) board_key() string {
+	// the seed is left for the first game
+	opts := []snake.SnakeOption{snake.WithRules(a.Config.Rules())}
+	if a.Level != nil {
+		opts = append(opts, snake.WithLevel(a.Level))
+	}
+	return snake.HighScoreKey(snake.CreateSnake(a.Config.Height, a.Config.Width, opts...))
+}
+
+// A setting of the options scene
+type option struct {
+	name  string
+	value func(c *snake.Config) string
+
+	// Change the setting to the next value, or to the previous one if step is -1
+	change func(c *snake.Config, step int)
+
+	// Copy the setting from src to dst, e.g. to the settings of the config file
+	save func(dst, src *snake.Config)
+}
+
+func bool_option(name string, field func(c *snake.Config) *bool) option {
+	return option{
+		name,
+		func(c *snake.Config) string {
+			if *field(c) {
+				return "on"
+			}
+			return "off"
+		},
+		func(c *snake.Config, step int) {
+			*field(c) = !*field(c)
+		},
+		func(dst, src *snake.Config) {
+			*field(dst) = *field(src)
+		},
+	}
+}
+
+var OPTIONS = []option{
+	{
+		"Speed",
+		func(c *snake.Config) string { return c.Speed },
+		func(c *snake.Config, step int) {
+			i := slices.IndexFunc(snake.DIFFICULTIES, func(d snake.Difficulty) bool { return d.Name == c.Speed })
+			c.Speed = snake.DIFFICULTIES[cycle(max(i, 0), step, len(snake.DIFFICULTIES))].Name
+		},
+		func(dst, src *snake.Config) { dst.Speed = src.Speed },
+	},
+	bool_option("Speed up", func(c *snake.Config) *bool { return &c.Progressive }),
+	{
+		"Board",
+		func(c *snake.Config) string { return fmt.Sprintf("%dx%d", c.Width, c.Height) },
+		func(c *snake.Config, step int) {
+			// a size set in the config file goes to the first or last size of the list
+			i := slices.Index(BOARD_SIZES, [2]int{c.Width, c.Height})
+			if i < 0 && step > 0 {
+				i = -1
+			} else if i < 0 {
+				i = 0
+			}
+			size := BOARD_SIZES[cycle(i, step, len(BOARD_SIZES))]
+			c.Width, c.Height = size[0], size[1]
+		},
+		func(dst, src *snake.Config) { dst.Width, dst.Height = src.Width, src.Height },
+	},
+	bool_option("Wrap", func(c *snake.Config) *bool { return &c.Wrap }),
+	{
+		"Lives",
+		func(c *snake.Config) string { return fmt.Sprint(c.Lives) },
+		func(c *snake.Config, step int) {
+			c.Lives = cycle(min(c.Lives, MAX_MENU_LIVES)-1, step, MAX_MENU_LIVES) + 1
+		},
+		func(dst, src *snake.Config) { dst.Lives = src.Lives },
+	},
+	bool_option("Items", func(c *snake.Config) *bool { return &c.Items }),
+	bool_option("Timed apples", func(c *snake.Config) *bool { return &c.Timed }),
+}
+
+// Change the settings of the games started from the menu
+type options_scene struct {
+	menu   menu
+	config snake.Config
+
+	// Options changed by the player, only these are saved
+	changed []bool
+}
+
+func new_options_scene(config snake.Config) *options_scene {
+	return &options_scene{menu{}, config, make([]bool, len(OPTIONS))}
+}
+
+func (o *options_scene) Update(a *App) error {
+	entries := len(OPTIONS) + 1
+	back := o.menu.update(a, entries) && o.menu.selected == len(OPTIONS)
+	for _, key := range inpututil.AppendJustPressedKeys(nil) {
+		step := menu_step(a, key)
+		if key == ebiten.KeyEscape {
+			back = true
+		}
+		if step != 0 && o.menu.selected < len(OPTIONS) {
+			OPTIONS[o.menu.selected].change(&o.config, step)
+			o.changed[o.menu.selected] = true
+		}
+	}
+	if back {
+		if slices.Contains(o.changed, true) {
+			a.set_config(o.config, o.save)
+		}
+		a.switch_to(new_title_scene(a))
+	}
+	return nil
+}
+
+// Copy the changed options to the settings of the config file
+func (o *options_scene) save(saved *snake.Config) {
+	for i, opt := range OPTIONS {
+		if o.changed[i] {
+			opt.save(saved, &o.config)
+		}
+	}
+}
+
+func (o *options_scene) Draw(screen *ebiten.Image) {
+	entries := []string{}
+	for _, opt := range OPTIONS {
+		entries = append(entries, fmt.Sprintf("%-14s < %s >", opt.name, opt.value(&o.config)))
+	}
+	entries = append(entries, "Back")
+	draw_menu(screen, "Options", entries, o.menu.selected)
+	draw_game_info(screen, screen.Bounds().Dx()/2-100, screen.Bounds().Dy()-40, "Left/Right to change, Esc to go back")
+}
+
+// Use the settings for the next games, and save the ones copied by save
+// if there is a config file
+func (a *App) set_config(config snake.Config, save func(saved *snake.Config)) {
+	a.Config = config
+	if a.ConfigPath == "" {
+		return
+	}
+	if err := save_options(a.ConfigPath, save); err != nil {
+		log.Printf("Failed to save the options: %v", err)
+	}
+}
+
+// Save the settings copied by save to a config file. The other settings
+// of the file are kept, the ones given on the command line are not saved
+func save_options(path string, save func(saved *snake.Config)) error {
+	saved, err := snake.LoadConfig(path)
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return err
+	}
+	save(&saved)
+	return snake.SaveConfig(path, saved)
+}
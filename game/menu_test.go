@@ -0,0 +1,97 @@
+package game
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+
+	"github.com/redwookcreek/snake/snake"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCycle(t *testing.T) {
+	assert.Equal(t, 1, cycle(0, 1, 3))
+	assert.Equal(t, 0, cycle(2, 1, 3))
+	assert.Equal(t, 2, cycle(0, -1, 3))
+}
+
+func find_option(name string) option {
+	for _, opt := range OPTIONS {
+		if opt.name == name {
+			return opt
+		}
+	}
+	panic("no option " + name)
+}
+
+func TestOptionsChangeConfig(t *testing.T) {
+	c := snake.DEFAULT_CONFIG
+
+	speed := find_option("Speed")
+	speed.change(&c, 1)
+	assert.Equal(t, "hard", speed.value(&c))
+	speed.change(&c, -1)
+	speed.change(&c, -1)
+	speed.change(&c, -1)
+	assert.Equal(t, "insane", c.Speed)
+
+	board := find_option("Board")
+	board.change(&c, 1)
+	assert.Equal(t, "30x20", board.value(&c))
+	// A size from the config file goes to the ends of the list
+	c.Width = 33
+	board.change(&c, 1)
+	assert.Equal(t, "10x10", board.value(&c))
+	c.Width = 33
+	board.change(&c, -1)
+	assert.Equal(t, "40x25", board.value(&c))
+
+	lives := find_option("Lives")
+	lives.change(&c, -1)
+	assert.Equal(t, MAX_MENU_LIVES, c.Lives)
+	lives.change(&c, 1)
+	assert.Equal(t, 1, c.Lives)
+
+	wrap := find_option("Wrap")
+	wrap.change(&c, 1)
+	assert.True(t, c.Wrap)
+	assert.Equal(t, "on", wrap.value(&c))
+	assert.NoError(t, c.Validate())
+}
+
+func TestSaveOptions(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.json")
+	assert.NoError(t, os.WriteFile(path, []byte(`{"seed": 5, "fullscreen": true, "lives": 2}`), 0644))
+
+	// Lives and items given on the command line, only Wrap changed in the menu
+	a := NewApp(snake.DEFAULT_CONFIG)
+	a.ConfigPath = path
+	a.Config.Seed = 9
+	a.Config.Lives = 4
+	a.Config.Items = true
+	o := new_options_scene(a.Config)
+	i := slices.IndexFunc(OPTIONS, func(opt option) bool { return opt.name == "Wrap" })
+	OPTIONS[i].change(&o.config, 1)
+	o.changed[i] = true
+	a.set_config(o.config, o.save)
+	assert.True(t, a.Config.Wrap)
+	assert.Equal(t, 4, a.Config.Lives)
+
+	saved, err := snake.LoadConfig(path)
+	assert.NoError(t, err)
+	expected := snake.DEFAULT_CONFIG
+	expected.Seed = 5
+	expected.Fullscreen = true
+	expected.Lives = 2
+	expected.Wrap = true
+	assert.Equal(t, expected, saved)
+}
+
+func TestSeedFirstGame(t *testing.T) {
+	config := snake.DEFAULT_CONFIG
+	config.Seed = 5
+	a := NewApp(config)
+	assert.Equal(t, uint64(5), a.NewGame().SnakeState.Seed)
+	assert.NotEqual(t, uint64(5), a.NewGame().SnakeState.Seed)
+}
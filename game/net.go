@@ -0,0 +1,164 @@
+package game
+
+import (
+	"fmt"
+	"sync"
+
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/hajimehoshi/ebiten/v2/inpututil"
+	"github.com/redwookcreek/snake/netplay"
+	"github.com/redwookcreek/snake/snake"
+)
+
+// Plays or watches a versus game on a server, the server moves the snakes
+// and this only sends the turns pressed and draws what it gets back
+type NetGame struct {
+	client *netplay.Client
+
+	// Game drawn, the latest state received is copied into it
+	state *snake.VersusState
+
+	// Wins of each player since joining
+	wins [snake.VERSUS_PLAYERS]int
+
+	// Turns pressed, one is sent for every tick
+	input snake.InputQueue
+
+	// Number of the game and tick answered last
+	game int
+	tick int
+
+	// Set by the receiving goroutine
+	mu     sync.Mutex
+	latest *netplay.State
+	err    error
+}
+
+func CreateNetGame(c *netplay.Client) *NetGame {
+	w := c.Welcome
+	g := &NetGame{
+		client: c,
+		state:  snake.CreateVersus(w.Height, w.Width, snake.WithRules(w.Rules)),
+		tick:   -1,
+	}
+	go g.receive_loop()
+	return g
+}
+
+func (g *NetGame) receive_loop() {
+	for {
+		st, err := g.client.Receive()
+		g.mu.Lock()
+		if err != nil {
+			g.err = err
+			g.mu.Unlock()
+			return
+		}
+		g.latest = st
+		g.mu.Unlock()
+	}
+}
+
+// Returns the state received since the last call, nil if none
+func (g *NetGame) take_latest() (*netplay.State, error) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	st := g.latest
+	g.latest = nil
+	return st, g.err
+}
+
+func (g *NetGame) spectating() bool {
+	return g.client.Welcome.Player == netplay.SPECTATOR
+}
+
+func (g *NetGame) Update() error {
+	player := g.client.Welcome.Player
+	var keys []ebiten.Key
+	if !g.spectating() {
+		keys = inpututil.AppendJustPressedKeys(keys)
+	}
+	for _, key := range keys {
+		// Both sets of keys move the player's snake
+		for _, player_keys := range VERSUS_KEYS {
+			if dir, ok := player_keys[key]; ok {
+				g.input.Push(dir, g.state.Snakes[player].Direction)
+			}
+		}
+	}
+
+	st, err := g.take_latest()
+	if err != nil || st == nil {
+		return nil
+	}
+	if st.Game != g.game {
+		// a new game started
+		g.game = st.Game
+		g.tick = -1
+		g.input.Clear()
+	}
+	if st.Tick <= g.tick {
+		return nil
+	}
+	g.tick = st.Tick
+	st.Apply(g.state)
+	if st.GameOver {
+		if st.Winner >= 0 && st.Winner < len(g.wins) {
+			g.wins[st.Winner] += 1
+		}
+		return nil
+	}
+	if g.spectating() {
+		return nil
+	}
+
+	current := g.state.Snakes[player].Direction
+	dir := g.input.Pop(current)
+	if dir == current {
+		dir = netplay.NO_TURN
+	}
+	if err := g.client.SendInput(st.Tick, dir); err != nil {
+		g.mu.Lock()
+		g.err = err
+		g.mu.Unlock()
+	}
+	return nil
+}
+
+func (g *NetGame) Draw(screen *ebiten.Image) {
+	draw_versus(screen, g.state)
+	x, y := screen.Bounds().Dx()/2-100, screen.Bounds().Dy()/2
+	g.mu.Lock()
+	err := g.err
+	g.mu.Unlock()
+	switch {
+	case err != nil:
+		draw_overlay(screen)
+		draw_game_info(screen, x, y, fmt.Sprintf("Disconnected: %v", err))
+	case g.game == 0 && g.spectating():
+		draw_overlay(screen)
+		draw_game_info(screen, x, y, "Waiting for a game to start")
+	case g.game == 0:
+		draw_overlay(screen)
+		draw_game_info(screen, x, y, fmt.Sprintf("You are player %d, waiting for the other player", g.client.Welcome.Player+1))
+	case g.state.GameOver:
+		draw_overlay(screen)
+		if g.state.Winner < 0 {
+			draw_game_info(screen, x, y, "Draw")
+		} else if g.spectating() {
+			draw_game_info(screen, x, y, fmt.Sprintf("Player %d wins!", g.state.Winner+1))
+		} else if g.state.Winner == g.client.Welcome.Player {
+			draw_game_info(screen, x, y, "You win!")
+		} else {
+			draw_game_info(screen, x, y, "You lose")
+		}
+		draw_game_info(screen, x, y+20, fmt.Sprintf("Wins: %d - %d", g.wins[0], g.wins[1]))
+		draw_game_info(screen, x, y+40, "The next game starts soon")
+	case g.spectating():
+		draw_game_info(screen, screen.Bounds().Dx()-120, screen.Bounds().Dy()-30, "Spectating")
+	}
+}
+
+func (g *NetGame) Layout(outsideWidth, outsideHeight int) (screenWidth, screenHeight int) {
+	return outsideWidth, outsideHeight
+}
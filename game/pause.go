@@ -0,0 +1,72 @@
+package game
+
+import (
+	"slices"
+
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/hajimehoshi/ebiten/v2/inpututil"
+	"github.com/hajimehoshi/ebiten/v2/vector"
+)
+
+// A game that can be paused and moved one tick at a time
+type pausable interface {
+	Scene
+
+	// True if the snakes can move
+	playing() bool
+
+	// Advance the game one tick
+	step()
+}
+
+// Shown over a game while it is paused
+type pause_scene struct {
+	game pausable
+
+	// Keys to resume the game, shown in the hint
+	resume_keys string
+}
+
+func new_pause_scene(a *App, g pausable) *pause_scene {
+	keys := append(slices.Clone(a.bindings[ACTION_PAUSE]), ebiten.KeyEscape)
+	return &pause_scene{g, key_names(keys)}
+}
+
+func (p *pause_scene) Update(a *App) error {
+	for _, key := range inpututil.AppendJustPressedKeys(nil) {
+		switch {
+		case a.bindings.is(ACTION_PAUSE, key) || key == ebiten.KeyEscape:
+			a.switch_to(p.game)
+			return nil
+		case key == ebiten.KeyN:
+			if p.game.playing() {
+				p.game.step()
+			}
+		case key == ebiten.KeyQ:
+			a.to_title()
+			return nil
+		}
+	}
+	if !p.game.playing() {
+		// ended while stepping
+		a.switch_to(p.game)
+	}
+	return nil
+}
+
+func (p *pause_scene) Draw(screen *ebiten.Image) {
+	p.game.Draw(screen)
+	draw_pause_screen(screen, p.resume_keys)
+}
+
+// Only a strip of the board is covered, so that the snake can be
+// watched while stepping
+func draw_pause_screen(screen *ebiten.Image, resume_keys string) {
+	vector.DrawFilledRect(
+		screen,
+		0, float32(screen.Bounds().Dy()/2-20),
+		float32(screen.Bounds().Dx()), 50,
+		OVERLAY_COLOR, false)
+	draw_game_info(screen, screen.Bounds().Dx()/2-25, screen.Bounds().Dy()/2, "Paused")
+	draw_game_info(screen, screen.Bounds().Dx()/2-130, screen.Bounds().Dy()/2+20, resume_keys+" to resume, N to step, Q for the menu")
+}
@@ -0,0 +1,97 @@
+package game
+
+import (
+	"errors"
+	"log"
+
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/redwookcreek/snake/snake"
+)
+
+// Seconds of play between two auto-saves
+const AUTOSAVE_SECONDS = 30
+
+// Saving of the game in progress, see EnableSave
+type save_state struct {
+	// File the game is saved to
+	path string
+
+	// Also save while playing, not only when the window is closed
+	autosave bool
+}
+
+// Save the game in progress to a file when the window is closed, and
+// every AUTOSAVE_SECONDS of play if autosave is set. The file is removed
+// once the game is over. Only games played with the keyboard are saved
+func (g *Game) EnableSave(path string, autosave bool) {
+	g.saving = &save_state{path, autosave}
+	ebiten.SetWindowClosingHandled(true)
+}
+
+// Create a game that goes on from a saved game. Reaching a level of
+// a saved campaign unlocks it in the progress saved to progress_path
+func ResumeGame(sg *snake.SavedGame, progress_path string) (*Game, error) {
+	if sg.Replay == nil {
+		return nil, errors.New("save has no recording of the game")
+	}
+	ss, err := sg.Restore()
+	if err != nil {
+		return nil, err
+	}
+	g := CreateGame(0, 0)
+	g.SnakeState = *ss
+	g.Speed = sg.Speed
+	g.ticker = sg.Ticker
+	g.play_frames = sg.Frames
+	g.replay = sg.Replay
+	if sg.Campaign != nil {
+		g.campaign = &campaign_state{sg.Campaign, load_progress(progress_path), progress_path, 0, false}
+	}
+	return g, nil
+}
+
+// True if the game is saved and can be saved now
+func (g *Game) saveable() bool {
+	return g.saving != nil && g.Controller == nil && g.playback == nil && g.playing()
+}
+
+func (g *Game) save() {
+	sg, err := snake.NewSavedGame(&g.SnakeState)
+	if err == nil {
+		sg.Speed = g.Speed
+		sg.Ticker = g.ticker
+		sg.Frames = g.play_frames
+		sg.Replay = g.replay
+		if g.campaign != nil {
+			sg.Campaign = g.campaign.Campaign
+		}
+		err = snake.SaveGame(g.saving.path, sg)
+	}
+	if err != nil {
+		log.Printf("Failed to save the game: %v", err)
+	}
+}
+
+// Called every frame played, saves every AUTOSAVE_SECONDS
+func (g *Game) maybe_autosave() {
+	if g.saveable() && g.saving.autosave && g.play_frames%uint64(AUTOSAVE_SECONDS*ebiten.TPS()) == 0 {
+		g.save()
+	}
+}
+
+// Called when the window is being closed
+func (g *Game) save_on_quit() {
+	if g.saveable() {
+		g.save()
+	}
+}
+
+// Forget the saved game, it cannot be resumed anymore
+func (g *Game) remove_save() {
+	if g.saving == nil || g.playback != nil || g.Controller != nil {
+		return
+	}
+	if err := snake.RemoveSave(g.saving.path); err != nil {
+		log.Printf("Failed to remove the saved game: %v", err)
+	}
+}
@@ -0,0 +1,51 @@
+package game
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/redwookcreek/snake/snake"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestResumeGame(t *testing.T) {
+	dir := t.TempDir()
+	progress_path := filepath.Join(dir, "campaign.json")
+	progress := snake.CampaignProgress{Unlocked: 1}
+	assert.NoError(t, snake.SaveProgress(progress_path, progress))
+
+	c, err := snake.NewCampaign(snake.Levels()[:2], 0)
+	assert.NoError(t, err)
+	c.TotalScore = 12
+	g := CreateCampaignGame(c, progress, progress_path, snake.WithSeed(5))
+	g.saving = &save_state{filepath.Join(dir, "save.json"), false}
+	for i := 0; i < 3; i++ {
+		g.step()
+	}
+	g.ticker = snake.Ticker{Elapsed: 4}
+	g.play_frames = 99
+	g.save()
+
+	sg, err := snake.LoadGame(g.saving.path)
+	assert.NoError(t, err)
+	resumed, err := ResumeGame(sg, progress_path)
+	assert.NoError(t, err)
+	assert.Equal(t, g.SnakeState, resumed.SnakeState)
+	assert.Equal(t, g.ticker, resumed.ticker)
+	assert.Equal(t, g.play_frames, resumed.play_frames)
+	assert.Equal(t, g.replay, resumed.replay)
+	assert.Equal(t, g.campaign.Campaign, resumed.campaign.Campaign)
+	assert.Equal(t, progress, resumed.campaign.progress)
+
+	// Both games go on the same way and record the same replay
+	for _, game := range []*Game{g, resumed} {
+		game.input.Push(snake.RIGHT, game.SnakeState.Direction)
+		for i := 0; i < 3; i++ {
+			game.step()
+		}
+	}
+	assert.False(t, g.SnakeState.GameOver)
+	assert.Equal(t, g.SnakeState, resumed.SnakeState)
+	assert.Equal(t, g.replay, resumed.replay)
+	assert.NoError(t, g.replay.Verify())
+}
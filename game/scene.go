@@ -0,0 +1,197 @@
+package game
+
+import (
+	"log"
+
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/redwookcreek/snake/snake"
+)
+
+// A screen of the game, e.g. the title menu or the game being played.
+// Only the current scene is updated and drawn, it picks the next one
+type Scene interface {
+	Update(a *App) error
+	Draw(screen *ebiten.Image)
+}
+
+// Runs the scenes, starting from the title menu
+type App struct {
+	// Settings of the games started from the menu, changed in the options
+	Config snake.Config
+
+	// If not empty, the options are saved to this file
+	ConfigPath string
+
+	// Level of the games started with Play, nil for an empty board
+	Level *snake.Level
+
+	// If not empty, the recording of every finished game is saved to this file
+	ReplayPath string
+
+	// If not empty, the high scores are kept in this file
+	HighScorePath string
+
+	// If not empty, the wins and deaths are kept in this file
+	StatsPath string
+
+	// If not empty, the game in progress is saved to this file, see EnableSave
+	SavePath string
+	AutoSave bool
+
+	// If not empty, the campaign progress is kept in this file
+	ProgressPath string
+
+	// Keys of the actions, from the config
+	bindings Bindings
+
+	scene Scene
+
+	// Game being played, saved when the window is closed
+	game *Game
+}
+
+// Create an app showing the title menu. The bindings of the config
+// should have been checked with ParseBindings, the defaults are used if not
+func NewApp(config snake.Config) *App {
+	bindings, err := ParseBindings(config.Bindings)
+	if err != nil {
+		log.Print(err)
+		bindings = DEFAULT_BINDINGS
+	}
+	a := &App{Config: config, bindings: bindings}
+	a.scene = new_title_scene(a)
+	return a
+}
+
+func (a *App) switch_to(scene Scene) {
+	a.scene = scene
+}
+
+// Play a game, e.g. one created with NewGame
+func (a *App) Play(g *Game) {
+	a.game = g
+	a.switch_to(g)
+}
+
+// Play a versus game with the settings of the config
+func (a *App) PlayVersus() error {
+	g, err := CreateVersusGame(a.Config.Height, a.Config.Width, a.Config.Rules(), a.seed_options()...)
+	if err != nil {
+		return err
+	}
+	g.Speed = a.Config.GameSpeed()
+	g.restart_keys = key_names(a.bindings[ACTION_RESTART])
+	a.game = nil
+	a.switch_to(g)
+	return nil
+}
+
+// Play the bundled levels from the furthest one unlocked, or from the
+// first one if restart is set. The levels unlocked stay unlocked
+func (a *App) PlayCampaign(restart bool) error {
+	if err := snake.CheckLevelRules(a.Config.Rules()); err != nil {
+		return err
+	}
+	levels := snake.Levels()
+	progress := load_progress(a.ProgressPath)
+	start := min(progress.Unlocked, len(levels)-1)
+	if restart {
+		start = 0
+	}
+	c, err := snake.NewCampaign(levels, start)
+	if err != nil {
+		return err
+	}
+	g := CreateCampaignGame(c, progress, a.ProgressPath, a.game_options()...)
+	a.setup(g)
+	a.Play(g)
+	return nil
+}
+
+// Returns a game with the settings of the config
+func (a *App) NewGame() *Game {
+	opts := a.game_options()
+	if a.Level != nil {
+		opts = append(opts, snake.WithLevel(a.Level))
+	}
+	g := CreateGame(a.Config.Height, a.Config.Width, opts...)
+	a.setup(g)
+	return g
+}
+
+func (a *App) game_options() []snake.SnakeOption {
+	return append([]snake.SnakeOption{snake.WithRules(a.Config.Rules())}, a.seed_options()...)
+}
+
+// Seed the first game started with the seed of the config, the games after it are random
+func (a *App) seed_options() []snake.SnakeOption {
+	if a.Config.Seed == 0 {
+		return nil
+	}
+	seed := a.Config.Seed
+	a.Config.Seed = 0
+	return []snake.SnakeOption{snake.WithSeed(seed)}
+}
+
+// Go on with a saved game
+func (a *App) Resume(sg *snake.SavedGame) error {
+	g, err := ResumeGame(sg, a.ProgressPath)
+	if err != nil {
+		return err
+	}
+	a.enable_files(g)
+	a.Play(g)
+	return nil
+}
+
+// Give a new game the speed and files of the app
+func (a *App) setup(g *Game) {
+	g.Speed = a.Config.GameSpeed()
+	a.enable_files(g)
+}
+
+func (a *App) enable_files(g *Game) {
+	g.ReplayPath = a.ReplayPath
+	if a.HighScorePath != "" {
+		if err := g.EnableHighScores(a.HighScorePath); err != nil {
+			log.Print(err)
+		}
+	}
+	if a.StatsPath != "" {
+		if err := g.EnableStats(a.StatsPath); err != nil {
+			log.Print(err)
+		}
+	}
+	if a.SavePath != "" {
+		g.EnableSave(a.SavePath, a.AutoSave)
+	}
+}
+
+// Leave the game being played for the title menu, a game
+// in progress is saved
+func (a *App) to_title() {
+	if a.game != nil {
+		a.game.save_on_quit()
+		a.game = nil
+	}
+	a.switch_to(new_title_scene(a))
+}
+
+func (a *App) Update() error {
+	if ebiten.IsWindowBeingClosed() {
+		if a.game != nil {
+			a.game.save_on_quit()
+		}
+		return ebiten.Termination
+	}
+	return a.scene.Update(a)
+}
+
+func (a *App) Draw(screen *ebiten.Image) {
+	a.scene.Draw(screen)
+}
+
+// The screen has the size of the window, the board is fitted in it by new_layout
+func (a *App) Layout(outsideWidth, outsideHeight int) (screenWidth, screenHeight int) {
+	return outsideWidth, outsideHeight
+}
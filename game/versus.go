@@ -0,0 +1,229 @@
+package game
+
+import (
+	"fmt"
+	"image/color"
+
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/hajimehoshi/ebiten/v2/inpututil"
+	"github.com/redwookcreek/snake/snake"
+)
+
+var (
+	// Keys of each player, player 1 on the left of the keyboard.
+	// They only steer the snakes, see check_versus_key
+	VERSUS_KEYS = [snake.VERSUS_PLAYERS]map[ebiten.Key]int{
+		{
+			ebiten.KeyW: snake.UP,
+			ebiten.KeyA: snake.LEFT,
+			ebiten.KeyS: snake.DOWN,
+			ebiten.KeyD: snake.RIGHT,
+		},
+		{
+			ebiten.KeyUp:    snake.UP,
+			ebiten.KeyLeft:  snake.LEFT,
+			ebiten.KeyDown:  snake.DOWN,
+			ebiten.KeyRight: snake.RIGHT,
+		},
+	}
+
+	VERSUS_KEY_NAMES = [snake.VERSUS_PLAYERS]string{"WASD", "arrows"}
+
+	// Tint of each player's snake
+	VERSUS_COLORS = [snake.VERSUS_PLAYERS]color.Color{
+		color.RGBA{0xff, 0xa0, 0x40, 0xff},
+		color.RGBA{0x60, 0xc0, 0xff, 0xff},
+	}
+
+	// Tint of a dead snake
+	DEAD_SNAKE_COLOR = color.RGBA{0x60, 0x60, 0x60, 0xff}
+)
+
+// True if the key steers the snake of a player in versus games
+func versus_key(key ebiten.Key) bool {
+	for _, player_keys := range VERSUS_KEYS {
+		if _, ok := player_keys[key]; ok {
+			return true
+		}
+	}
+	return false
+}
+
+// Two players on the same keyboard
+type VersusGame struct {
+	State *snake.VersusState
+
+	// How fast the snakes move
+	Speed snake.Speed
+
+	// Wins of each player since start
+	Wins [snake.VERSUS_PLAYERS]int
+
+	// Options to create the next game with
+	height int
+	width  int
+	rules  snake.Rules
+
+	ticker snake.Ticker
+
+	// Turns pressed by each player
+	inputs [snake.VERSUS_PLAYERS]snake.InputQueue
+
+	// Names of the keys to play again, shown when a game is over
+	restart_keys string
+}
+
+// Create a versus game, the options only apply to the first game, e.g. its seed
+func CreateVersusGame(height, width int, rules snake.Rules, opts ...snake.SnakeOption) (*VersusGame, error) {
+	if err := snake.CheckVersusRules(rules); err != nil {
+		return nil, err
+	}
+	g := &VersusGame{
+		nil,
+		snake.DEFAULT_SPEED,
+		[snake.VERSUS_PLAYERS]int{},
+		height,
+		width,
+		rules,
+		snake.Ticker{},
+		[snake.VERSUS_PLAYERS]snake.InputQueue{},
+		key_names(DEFAULT_BINDINGS[ACTION_RESTART]),
+	}
+	g.start_game(opts...)
+	return g, nil
+}
+
+func (g *VersusGame) RestartGame() {
+	g.start_game()
+}
+
+// Start a new game, keeping the board size, rules and wins
+func (g *VersusGame) start_game(opts ...snake.SnakeOption) {
+	opts = append([]snake.SnakeOption{snake.WithRules(g.rules)}, opts...)
+	g.State = snake.CreateVersus(g.height, g.width, opts...)
+	for i := range g.inputs {
+		g.inputs[i].Clear()
+	}
+	g.ticker.Reset()
+}
+
+// True if the snakes can move
+func (g *VersusGame) playing() bool {
+	return !g.State.GameOver
+}
+
+// Move the snakes one tick with the turns each player pressed
+func (g *VersusGame) step() {
+	for i := range g.State.Snakes {
+		g.State.UpdateDirection(i, g.inputs[i].Pop(g.State.Snakes[i].Direction))
+	}
+	g.State.Tick()
+	if g.State.GameOver && g.State.Winner >= 0 {
+		g.Wins[g.State.Winner] += 1
+	}
+}
+
+func (g *VersusGame) Update(a *App) error {
+	var keys []ebiten.Key
+	keys = inpututil.AppendJustPressedKeys(keys)
+	actions := []Action{}
+	for _, key := range keys {
+		turned := false
+		for i, player_keys := range VERSUS_KEYS {
+			if dir, ok := player_keys[key]; ok {
+				g.inputs[i].Push(dir, g.State.Snakes[i].Direction)
+				turned = true
+			}
+		}
+		if !turned {
+			// the keys of the players only turn the snakes
+			actions = append(actions, a.bindings.actions([]ebiten.Key{key})...)
+		}
+	}
+	for _, action := range actions {
+		switch action {
+		case ACTION_RESTART:
+			g.RestartGame()
+		case ACTION_PAUSE:
+			if g.playing() {
+				a.switch_to(new_pause_scene(a, g))
+				return nil
+			}
+		}
+	}
+	if inpututil.IsKeyJustPressed(ebiten.KeyEscape) {
+		// Escape pauses too, and leaves a finished game for the menu
+		if g.playing() {
+			a.switch_to(new_pause_scene(a, g))
+		} else {
+			a.to_title()
+		}
+		return nil
+	}
+
+	// Pause when the window loses focus
+	if !ebiten.IsFocused() && g.playing() {
+		a.switch_to(new_pause_scene(a, g))
+		return nil
+	}
+	if !g.playing() {
+		return nil
+	}
+	apples := 0
+	for _, s := range g.State.Snakes {
+		apples += s.Score
+	}
+	ticks := g.ticker.Frame(ebiten.TPS(), g.Speed.TickInterval(apples))
+	for i := 0; i < ticks && !g.State.GameOver; i++ {
+		g.step()
+	}
+	return nil
+}
+
+func (g *VersusGame) Draw(screen *ebiten.Image) {
+	draw_versus(screen, g.State)
+	if g.State.GameOver {
+		draw_versus_result(screen, g.State.Winner, g.Wins, g.restart_keys)
+	}
+}
+
+// Draw the board, the snakes and their scores
+func draw_versus(screen *ebiten.Image, vs *snake.VersusState) {
+	board := vs.Board
+	l := new_layout(screen.Bounds().Dx(), screen.Bounds().Dy(), board.Width, board.Height)
+
+	if !board.Rules.Wrap {
+		draw_boarder(screen, l)
+	}
+
+	for i, s := range vs.Snakes {
+		info := fmt.Sprintf("P%d (%s): %d", i+1, VERSUS_KEY_NAMES[i], s.Score)
+		draw_game_info(screen, HUD_X+i*200, hud_y(0), info)
+
+		var tint ebiten.ColorScale
+		if s.Dead {
+			tint.ScaleWithColor(DEAD_SNAKE_COLOR)
+		} else {
+			tint.ScaleWithColor(VERSUS_COLORS[i])
+		}
+		for _, snake_part := range s.SnakeBody {
+			draw_snake_part(screen, snake_part, tint, l)
+		}
+	}
+
+	if board.HasApple() {
+		draw_apple(screen, board.Apple, l)
+	}
+}
+
+func draw_versus_result(screen *ebiten.Image, winner int, wins [snake.VERSUS_PLAYERS]int, restart_keys string) {
+	draw_overlay(screen)
+	x, y := screen.Bounds().Dx()/2-60, screen.Bounds().Dy()/2
+	if winner < 0 {
+		draw_game_info(screen, x, y, "Draw")
+	} else {
+		draw_game_info(screen, x, y, fmt.Sprintf("Player %d wins!", winner+1))
+	}
+	draw_game_info(screen, x, y+20, fmt.Sprintf("Wins: %d - %d", wins[0], wins[1]))
+	draw_game_info(screen, x, y+40, restart_keys+" to play again, Esc for the menu")
+}
@@ -1,16 +1,318 @@
 package main
 
 import (
+	"flag"
+	"fmt"
 	"log"
+	"net"
+	"os"
+	"strings"
 
 	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/redwookcreek/snake/bot"
+	"github.com/redwookcreek/snake/game"
+	"github.com/redwookcreek/snake/netplay"
 	"github.com/redwookcreek/snake/snake"
 )
 
+const USAGE = `Usage:
+  snake [-config file] [-width n] [-height n] [-window WxH] [-fullscreen] [-seed n]
+        [-speed name] [-progressive] [-wrap] [-lives n] [-items] [-timed] [-level name]
+        [-record file] [-bot name] [-campaign] [-new-campaign] [-versus] [-save]
+        [-autosave]                  play the game from the title menu, or right away
+                                     with -campaign, -new-campaign, -versus or -bot.
+                                     The settings not given come from the config file
+  snake resume [-file path] [-record file] [-autosave]
+                                     go on with the game saved on quit
+  snake replay [-verify] file        watch a recorded game
+  snake bench [-bots names] [-sizes 10x10,20x20] [-games n] [-seed n] [-json]
+                                     compare bots over headless games
+  snake serve [-addr host:port] [-speed name] [-wrap] [-seed n]
+                                     host versus games over the network
+  snake join [-name name] [-watch] host:port
+                                     play a versus game on a server, or watch it
+`
+
+// Port of the server if not given
+const DEFAULT_ADDR = ":7777"
+
 func main() {
-	ebiten.SetWindowSize(640, 640)
+	flag.Usage = func() { fmt.Fprint(os.Stderr, USAGE) }
+	if len(os.Args) > 1 {
+		switch os.Args[1] {
+		case "resume":
+			resume(os.Args[2:])
+			return
+		case "replay":
+			replay(os.Args[2:])
+			return
+		case "bench":
+			bench(os.Args[2:])
+			return
+		case "serve":
+			serve(os.Args[2:])
+			return
+		case "join":
+			join(os.Args[2:])
+			return
+		}
+	}
+	play(os.Args[1:])
+}
+
+func play(args []string) {
+	flags := flag.NewFlagSet("snake", flag.ExitOnError)
+	flags.Usage = flag.Usage
+	config_path := flags.String("config", "", "config file with the default settings, see DefaultConfigPath")
+	// the flags are applied on top of the config file by load_config
+	defaults := snake.DEFAULT_CONFIG
+	config_flags(flags, &defaults)
+	record := flags.String("record", "", "save the recording of each finished game to this file")
+	bot_name := flags.String("bot", "", "let a bot play: "+strings.Join(bot.NAMES, ", "))
+	campaign := flags.Bool("campaign", false, "play the bundled levels in order, from the furthest one unlocked")
+	new_campaign := flags.Bool("new-campaign", false, "play the bundled levels in order, from the first one")
+	versus := flags.Bool("versus", false, "two players on one keyboard, WASD against the arrow keys")
+	save := flags.Bool("save", false, "save the game in progress when the window is closed, see resume")
+	autosave := flags.Bool("autosave", false, "also save the game in progress every few seconds, implies -save")
+	flags.Parse(args)
+
+	config, config_file, err := load_config(*config_path, flags)
+	if err != nil {
+		log.Fatal(err)
+	}
+	if _, err := game.ParseBindings(config.Bindings); err != nil {
+		log.Fatal(err)
+	}
+	app := game.NewApp(config)
+	app.ConfigPath = config_file
+	app.ReplayPath = *record
+	app.ProgressPath = default_path("Campaign progress", snake.DefaultProgressPath)
+	if config.Level != "" {
+		if app.Level, err = load_level(config.Level); err != nil {
+			log.Fatal(err)
+		}
+	}
+	if *bot_name == "" {
+		app.HighScorePath = default_path("High scores", snake.DefaultHighScorePath)
+		app.StatsPath = default_path("Stats", snake.DefaultStatsPath)
+		if *save || *autosave {
+			app.SavePath = default_path("The game in progress", snake.DefaultSavePath)
+			app.AutoSave = *autosave
+		}
+	}
+
+	// Without a mode the title menu is shown
+	switch {
+	case *versus:
+		if *campaign || *new_campaign || config.Level != "" || *bot_name != "" || *record != "" || *save || *autosave {
+			log.Fatal("-versus cannot be used with -campaign, -level, -bot, -record or -save")
+		}
+		if err := app.PlayVersus(); err != nil {
+			log.Fatal(err)
+		}
+	case *campaign || *new_campaign:
+		if config.Level != "" {
+			log.Fatal("-campaign cannot be used with -level")
+		}
+		if err := app.PlayCampaign(*new_campaign); err != nil {
+			log.Fatal(err)
+		}
+	case *bot_name != "":
+		c, err := bot.New(*bot_name)
+		if err != nil {
+			log.Fatal(err)
+		}
+		g := app.NewGame()
+		g.Controller = c
+		app.Play(g)
+	}
+	run_game(app, config)
+}
+
+// Go on with the game saved when the window was closed
+func resume(args []string) {
+	flags := flag.NewFlagSet("resume", flag.ExitOnError)
+	flags.Usage = flag.Usage
+	file := flags.String("file", "", "saved game to resume, the one saved on quit if not given")
+	record := flags.String("record", "", "save the recording of each finished game to this file")
+	autosave := flags.Bool("autosave", false, "also save the game every few seconds")
+	flags.Parse(args)
+
+	path := *file
+	if path == "" {
+		var err error
+		if path, err = snake.DefaultSavePath(); err != nil {
+			log.Fatal(err)
+		}
+	}
+	sg, err := snake.LoadGame(path)
+	if err != nil {
+		log.Fatal(err)
+	}
+	config, config_file := default_config()
+	app := game.NewApp(config)
+	app.ConfigPath = config_file
+	app.ReplayPath = *record
+	app.ProgressPath = default_path("Campaign progress", snake.DefaultProgressPath)
+	app.HighScorePath = default_path("High scores", snake.DefaultHighScorePath)
+	app.StatsPath = default_path("Stats", snake.DefaultStatsPath)
+	// closing the window saves the game again
+	app.SavePath = path
+	app.AutoSave = *autosave
+	if err := app.Resume(sg); err != nil {
+		log.Fatal(err)
+	}
+	run_game(app, config)
+}
+
+// Returns the default file for something kept between runs,
+// or an empty path if it cannot be kept
+func default_path(what string, path_func func() (string, error)) string {
+	path, err := path_func()
+	if err != nil {
+		log.Printf("%s will not be saved: %v", what, err)
+		return ""
+	}
+	return path
+}
+
+// Load a level file, or a bundled level if there is no such file
+func load_level(name string) (*snake.Level, error) {
+	if _, err := os.Stat(name); err == nil {
+		return snake.LoadLevel(name)
+	}
+	return snake.FindLevel(name)
+}
+
+func difficulty_names() string {
+	names := []string{}
+	for _, d := range snake.DIFFICULTIES {
+		names = append(names, d.Name)
+	}
+	return strings.Join(names, ", ")
+}
+
+func replay(args []string) {
+	flags := flag.NewFlagSet("replay", flag.ExitOnError)
+	flags.Usage = flag.Usage
+	verify := flags.Bool("verify", false, "play back without a window and check the final score")
+	flags.Parse(args)
+	if flags.NArg() != 1 {
+		flag.Usage()
+		os.Exit(2)
+	}
+
+	r, err := snake.LoadReplay(flags.Arg(0))
+	if err != nil {
+		log.Fatal(err)
+	}
+	if *verify {
+		if err := r.Verify(); err != nil {
+			log.Fatal(err)
+		}
+		fmt.Printf("OK: score %d after %d ticks\n", r.Score, r.Ticks())
+		return
+	}
+	config, _ := default_config()
+	app := game.NewApp(config)
+	app.Play(game.CreatePlaybackGame(r))
+	run_game(app, config)
+}
+
+func bench(args []string) {
+	flags := flag.NewFlagSet("bench", flag.ExitOnError)
+	flags.Usage = flag.Usage
+	bots := flags.String("bots", strings.Join(bot.NAMES, ","), "comma separated bots to compare")
+	sizes := flags.String("sizes", "10x10,20x20", "comma separated board sizes, width x height")
+	games := flags.Int("games", 100, "games per bot and board size")
+	seed := flags.Uint64("seed", 1, "seed of the first game")
+	as_json := flags.Bool("json", false, "print the results as JSON instead of a table")
+	flags.Parse(args)
+
+	config := bot.BenchConfig{Bots: strings.Split(*bots, ","), Games: *games, Seed: *seed}
+	for _, s := range strings.Split(*sizes, ",") {
+		size, err := bot.ParseSize(s)
+		if err != nil {
+			log.Fatal(err)
+		}
+		config.Sizes = append(config.Sizes, size)
+	}
+
+	results, err := bot.Bench(config)
+	if err != nil {
+		log.Fatal(err)
+	}
+	if *as_json {
+		err = bot.WriteJSON(os.Stdout, results)
+	} else {
+		err = bot.WriteTable(os.Stdout, results)
+	}
+	if err != nil {
+		log.Fatal(err)
+	}
+}
+
+func serve(args []string) {
+	flags := flag.NewFlagSet("serve", flag.ExitOnError)
+	flags.Usage = flag.Usage
+	addr := flags.String("addr", DEFAULT_ADDR, "address to listen on")
+	difficulty := flags.String("speed", "normal", "how fast the snakes move: "+difficulty_names())
+	wrap := flags.Bool("wrap", false, "no boarder, the snakes wrap around the edges")
+	seed := flags.Uint64("seed", 1, "seed of the first game")
+	flags.Parse(args)
+
+	d, err := snake.FindDifficulty(*difficulty)
+	if err != nil {
+		log.Fatal(err)
+	}
+	config := netplay.DEFAULT_SERVER_CONFIG
+	config.Rules = snake.Rules{Wrap: *wrap}
+	config.TickInterval = d.Interval
+	config.Seed = *seed
+
+	l, err := net.Listen("tcp", *addr)
+	if err != nil {
+		log.Fatal(err)
+	}
+	log.Printf("Waiting for players on %s", l.Addr())
+	if err := netplay.NewServer(config).Serve(l); err != nil {
+		log.Fatal(err)
+	}
+}
+
+func join(args []string) {
+	flags := flag.NewFlagSet("join", flag.ExitOnError)
+	flags.Usage = flag.Usage
+	name := flags.String("name", os.Getenv("USER"), "name shown to the other player")
+	watch := flags.Bool("watch", false, "watch the games without playing")
+	flags.Parse(args)
+	if flags.NArg() != 1 {
+		flag.Usage()
+		os.Exit(2)
+	}
+
+	connect := netplay.Dial
+	if *watch {
+		connect = netplay.Spectate
+	}
+	c, err := connect(flags.Arg(0), *name)
+	if err != nil {
+		log.Fatal(err)
+	}
+	defer c.Close()
+	config, _ := default_config()
+	run_game(game.CreateNetGame(c), config)
+}
+
+func run_game(game ebiten.Game, config snake.Config) {
+	ebiten.SetWindowSize(config.WindowWidth, config.WindowHeight)
+	ebiten.SetFullscreen(config.Fullscreen)
+	// the board keeps square cells in any window, see game.Layout
+	ebiten.SetWindowResizingMode(ebiten.WindowResizingModeEnabled)
 	ebiten.SetWindowTitle("Snake")
-	if err := ebiten.RunGame(snake.CreateGame(20, 20)); err != nil {
+	// Keep updating without focus, so that the game can pause itself
+	ebiten.SetRunnableOnUnfocused(true)
+	if err := ebiten.RunGame(game); err != nil {
 		log.Fatal(err)
 	}
 }
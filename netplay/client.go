@@ -0,0 +1,124 @@
+package netplay
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"sync"
+	"time"
+
+	"github.com/redwookcreek/snake/snake"
+)
+
+// Time to wait for the server to welcome a client
+const DIAL_TIMEOUT = 10 * time.Second
+
+// A player or spectator connected to a server
+type Client struct {
+	// Game the client joined
+	Welcome Welcome
+
+	conn net.Conn
+	dec  *decoder
+
+	// State received last, deltas are applied to it
+	state *State
+
+	// Inputs are sent from the game loop while states are received
+	write_mu sync.Mutex
+}
+
+// Connect to a server and join its game
+func Dial(addr, name string) (*Client, error) {
+	return connect(addr, Join{name, false})
+}
+
+// Connect to a server to watch its games
+func Spectate(addr, name string) (*Client, error) {
+	return connect(addr, Join{name, true})
+}
+
+func connect(addr string, join Join) (*Client, error) {
+	conn, err := net.DialTimeout("tcp", addr, DIAL_TIMEOUT)
+	if err != nil {
+		return nil, err
+	}
+	c := &Client{conn: conn, dec: new_decoder(conn)}
+	if err := c.join(join); err != nil {
+		conn.Close()
+		return nil, err
+	}
+	return c, nil
+}
+
+func (c *Client) join(join Join) error {
+	if err := c.send(&Message{Type: MSG_JOIN, Join: &join}); err != nil {
+		return err
+	}
+	c.conn.SetReadDeadline(time.Now().Add(DIAL_TIMEOUT))
+	defer c.conn.SetReadDeadline(time.Time{})
+	msg, err := c.receive()
+	if err != nil {
+		return err
+	}
+	if msg.Type != MSG_WELCOME || msg.Welcome == nil {
+		return fmt.Errorf("expected a welcome message, got %q", msg.Type)
+	}
+	if p := msg.Welcome.Player; p != SPECTATOR && (p < 0 || p >= snake.VERSUS_PLAYERS) {
+		return fmt.Errorf("welcome to invalid player %d", p)
+	}
+	c.Welcome = *msg.Welcome
+	return nil
+}
+
+// Wait for the state of the next tick
+func (c *Client) Receive() (*State, error) {
+	for {
+		msg, err := c.receive()
+		if err != nil {
+			return nil, err
+		}
+		switch {
+		case msg.Type == MSG_STATE && msg.State != nil:
+			c.state = msg.State
+			return c.state, nil
+		case msg.Type == MSG_DELTA && msg.Delta != nil:
+			if c.state == nil {
+				return nil, errors.New("delta before any state")
+			}
+			state, err := c.state.ApplyDelta(msg.Delta)
+			if err != nil {
+				return nil, err
+			}
+			c.state = state
+			return c.state, nil
+		}
+	}
+}
+
+// Returns the next message, an error message from the server is an error
+func (c *Client) receive() (*Message, error) {
+	msg, err := c.dec.decode()
+	if err != nil {
+		return nil, err
+	}
+	if msg.Type == MSG_ERROR {
+		return nil, errors.New(msg.Error)
+	}
+	return msg, nil
+}
+
+// Answer the state of a tick with the direction to turn to, or NO_TURN
+func (c *Client) SendInput(tick, dir int) error {
+	return c.send(&Message{Type: MSG_INPUT, Input: &Input{tick, dir}})
+}
+
+func (c *Client) send(msg *Message) error {
+	c.write_mu.Lock()
+	defer c.write_mu.Unlock()
+	return encode(c.conn, msg)
+}
+
+func (c *Client) Close() error {
+	return c.conn.Close()
+}
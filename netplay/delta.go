@@ -0,0 +1,110 @@
+package netplay
+
+import (
+	"fmt"
+
+	"github.com/redwookcreek/snake/snake"
+)
+
+// Changes of the game from one tick to the next, sent to spectators
+// instead of the whole state
+type Delta struct {
+	Game int `json:"game"`
+
+	// Tick of the state after the changes
+	Tick int `json:"tick"`
+
+	Snakes   []SnakeDelta `json:"snakes"`
+	Apple    snake.Point  `json:"apple"`
+	GameOver bool         `json:"game_over"`
+	Winner   int          `json:"winner"`
+}
+
+// Changes of a snake from one tick to the next. The new body is
+// the old one with Cut parts cut off the tail and Replace parts
+// off the head, the new tail turned to Tail, and Head added
+type SnakeDelta struct {
+	Cut     int               `json:"cut,omitempty"`
+	Replace int               `json:"replace,omitempty"`
+	Tail    int               `json:"tail"`
+	Head    []snake.SnakePart `json:"head,omitempty"`
+
+	Direction int  `json:"dir"`
+	Score     int  `json:"score"`
+	Dead      bool `json:"dead"`
+}
+
+// Returns the changes from the state of a tick to the state of the next tick
+func NewDelta(from, to *State) *Delta {
+	d := &Delta{to.Game, to.Tick, make([]SnakeDelta, len(to.Snakes)), to.Apple, to.GameOver, to.Winner}
+	for i, s := range to.Snakes {
+		d.Snakes[i] = diff_body(from.Snakes[i].SnakeBody, s.SnakeBody)
+		d.Snakes[i].Direction = s.Direction
+		d.Snakes[i].Score = s.Score
+		d.Snakes[i].Dead = s.Dead
+	}
+	return d
+}
+
+// Returns the state after the changes, st is not changed
+func (st *State) ApplyDelta(d *Delta) (*State, error) {
+	if d.Game != st.Game || d.Tick != st.Tick+1 || len(d.Snakes) != len(st.Snakes) {
+		return nil, fmt.Errorf(
+			"delta for game %d tick %d does not follow game %d tick %d",
+			d.Game, d.Tick, st.Game, st.Tick)
+	}
+	next := &State{d.Game, d.Tick, make([]snake.VersusSnake, len(st.Snakes)), d.Apple, d.GameOver, d.Winner}
+	for i, sd := range d.Snakes {
+		body, err := apply_body(st.Snakes[i].SnakeBody, sd)
+		if err != nil {
+			return nil, err
+		}
+		next.Snakes[i] = snake.VersusSnake{
+			SnakeBody: body,
+			Direction: sd.Direction,
+			Score:     sd.Score,
+			Dead:      sd.Dead,
+		}
+	}
+	return next, nil
+}
+
+// Returns the delta that turns the old body into the new one.
+// The parts of a snake are on different cells, so the new tail is found
+// in the old body by its cell, the parts after it are kept as long as
+// they did not change
+func diff_body(old, new []snake.SnakePart) SnakeDelta {
+	if len(new) == 0 {
+		return SnakeDelta{Cut: len(old)}
+	}
+	for cut, part := range old {
+		if part.Cord != new[0].Cord {
+			continue
+		}
+		keep := 1
+		for cut+keep < len(old) && keep < len(new) && old[cut+keep] == new[keep] {
+			keep += 1
+		}
+		return SnakeDelta{
+			Cut:     cut,
+			Replace: len(old) - cut - keep,
+			Tail:    new[0].PartType,
+			Head:    new[keep:],
+		}
+	}
+	// Nothing in common, send the whole body
+	return SnakeDelta{Cut: len(old), Head: new}
+}
+
+// Returns a new body with the delta applied to the old one
+func apply_body(old []snake.SnakePart, d SnakeDelta) ([]snake.SnakePart, error) {
+	if d.Cut < 0 || d.Replace < 0 || d.Cut+d.Replace > len(old) {
+		return nil, fmt.Errorf("cannot cut %d and replace %d parts of %d", d.Cut, d.Replace, len(old))
+	}
+	body := make([]snake.SnakePart, 0, len(old)-d.Cut-d.Replace+len(d.Head))
+	body = append(body, old[d.Cut:len(old)-d.Replace]...)
+	if len(body) > 0 {
+		body[0].PartType = d.Tail
+	}
+	return append(body, d.Head...), nil
+}
@@ -0,0 +1,67 @@
+package netplay
+
+import (
+	"testing"
+
+	"github.com/redwookcreek/snake/snake"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDelta(t *testing.T) {
+	vs := snake.CreateVersus(10, 10, snake.WithSeed(3))
+	prev := NewState(1, 0, vs)
+	for tick := 1; !vs.GameOver && tick < 60; tick++ {
+		for i, script := range TEST_SCRIPTS {
+			if dir := script[(tick-1)%len(script)]; dir != NO_TURN {
+				vs.UpdateDirection(i, dir)
+			}
+		}
+		vs.Tick()
+		next := NewState(1, tick, vs)
+		d := NewDelta(prev, next)
+		// Only the head and the parts next to it are sent
+		for _, sd := range d.Snakes {
+			assert.LessOrEqual(t, len(sd.Head), 2)
+		}
+		applied, err := prev.ApplyDelta(d)
+		assert.NoError(t, err)
+		assert.Equal(t, next, applied)
+		prev = next
+	}
+}
+
+func TestDeltaOutOfOrder(t *testing.T) {
+	vs := snake.CreateVersus(10, 10)
+	st := NewState(1, 5, vs)
+	_, err := st.ApplyDelta(NewDelta(st, NewState(1, 7, vs)))
+	assert.Error(t, err)
+	_, err = st.ApplyDelta(NewDelta(st, NewState(2, 6, vs)))
+	assert.Error(t, err)
+}
+
+func TestDiffBody(t *testing.T) {
+	part := func(x, y, t int) snake.SnakePart {
+		return snake.SnakePart{Cord: snake.Point{X: x, Y: y}, PartType: t}
+	}
+	old := []snake.SnakePart{
+		part(1, 1, snake.BODY_PART_TAIL_RIGHT),
+		part(2, 1, snake.BODY_PART_H),
+		part(3, 1, snake.BODY_PART_HEAD_RIGHT)}
+	for _, new := range [][]snake.SnakePart{
+		// moved
+		{part(2, 1, snake.BODY_PART_TAIL_RIGHT), part(3, 1, snake.BODY_PART_H), part(4, 1, snake.BODY_PART_HEAD_RIGHT)},
+		// grew
+		append(old[:2:2], part(3, 1, snake.BODY_PART_H), part(4, 1, snake.BODY_PART_HEAD_RIGHT)),
+		// nothing in common
+		{part(5, 5, snake.BODY_PART_HEAD_UP)},
+		// gone
+		{},
+	} {
+		body, err := apply_body(old, diff_body(old, new))
+		assert.NoError(t, err)
+		assert.Equal(t, new, body)
+	}
+
+	_, err := apply_body(old, SnakeDelta{Cut: 2, Replace: 2})
+	assert.Error(t, err)
+}
@@ -0,0 +1,254 @@
+package netplay
+
+import (
+	"net"
+	"testing"
+	"time"
+
+	"github.com/redwookcreek/snake/snake"
+	"github.com/stretchr/testify/assert"
+)
+
+func start_server(t *testing.T, config ServerConfig) string {
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	s := NewServer(config)
+	go s.Serve(l)
+	t.Cleanup(func() { s.Close() })
+	return l.Addr().String()
+}
+
+func dial(t *testing.T, addr, name string) *Client {
+	c, err := Dial(addr, name)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { c.Close() })
+	return c
+}
+
+func test_config(width, height int, grace time.Duration) ServerConfig {
+	config := DEFAULT_SERVER_CONFIG
+	config.Width = width
+	config.Height = height
+	config.TickInterval = time.Millisecond
+	config.LateInputGrace = grace
+	config.RestartDelay = time.Hour
+	config.Seed = 7
+	return config
+}
+
+// Each snake goes round a square, away from the other one
+var TEST_SCRIPTS = [snake.VERSUS_PLAYERS][]int{
+	{NO_TURN, snake.RIGHT, NO_TURN, snake.DOWN, NO_TURN, snake.LEFT, NO_TURN, snake.UP},
+	{NO_TURN, snake.LEFT, NO_TURN, snake.DOWN, NO_TURN, snake.RIGHT, NO_TURN, snake.UP},
+}
+
+func TestLockstepGame(t *testing.T) {
+	// The server always waits for both players
+	addr := start_server(t, test_config(10, 10, time.Minute))
+	clients := []*Client{dial(t, addr, "alice"), dial(t, addr, "bob")}
+	assert.Equal(t, 0, clients[0].Welcome.Player)
+	assert.Equal(t, 1, clients[1].Welcome.Player)
+	assert.Equal(t, 10, clients[1].Welcome.Width)
+
+	const TICKS = 40
+	results := make(chan []*State, len(clients))
+	for _, c := range clients {
+		go func(c *Client) {
+			states := []*State{}
+			for len(states) < TICKS {
+				st, err := c.Receive()
+				if err != nil {
+					break
+				}
+				states = append(states, st)
+				if st.GameOver {
+					break
+				}
+				script := TEST_SCRIPTS[c.Welcome.Player]
+				c.SendInput(st.Tick, script[st.Tick%len(script)])
+			}
+			results <- states
+		}(c)
+	}
+	seen := [][]*State{<-results, <-results}
+	assert.Equal(t, seen[0], seen[1])
+
+	// The same game played locally
+	vs := snake.CreateVersus(10, 10, snake.WithSeed(7))
+	assert.NotEmpty(t, seen[0])
+	for tick, st := range seen[0] {
+		assert.Equal(t, NewState(1, tick, vs), st)
+		for i, script := range TEST_SCRIPTS {
+			if dir := script[tick%len(script)]; dir != NO_TURN {
+				vs.UpdateDirection(i, dir)
+			}
+		}
+		vs.Tick()
+	}
+}
+
+func TestLateInput(t *testing.T) {
+	addr := start_server(t, test_config(20, 20, 10*time.Millisecond))
+	alice := dial(t, addr, "alice")
+	bob := dial(t, addr, "bob")
+
+	// Bob never answers in time, his turn for tick 0 comes after tick 3
+	go func() {
+		sent := false
+		for {
+			st, err := bob.Receive()
+			if err != nil {
+				return
+			}
+			if st.Tick >= 3 && !sent {
+				bob.SendInput(0, snake.LEFT)
+				sent = true
+			}
+		}
+	}()
+
+	turned := -1
+	for turned < 0 {
+		st, err := alice.Receive()
+		if err != nil || st.GameOver {
+			t.Fatal("game ended before bob turned", err)
+		}
+		if st.Snakes[1].Direction == snake.LEFT {
+			turned = st.Tick
+		}
+		alice.SendInput(st.Tick, NO_TURN)
+	}
+	assert.Greater(t, turned, 3)
+}
+
+func TestInvalidInput(t *testing.T) {
+	addr := start_server(t, test_config(20, 20, 10*time.Millisecond))
+	alice := dial(t, addr, "alice")
+	bob := dial(t, addr, "bob")
+
+	for _, c := range []*Client{alice, bob} {
+		st, err := c.Receive()
+		assert.NoError(t, err)
+		assert.Equal(t, 0, st.Tick)
+	}
+	alice.SendInput(0, 42)
+	bob.SendInput(0, NO_TURN)
+
+	st, err := alice.Receive()
+	assert.NoError(t, err)
+	assert.Equal(t, 1, st.Tick)
+	assert.Equal(t, snake.UP, st.Snakes[alice.Welcome.Player].Direction)
+}
+
+func TestGameIsFull(t *testing.T) {
+	addr := start_server(t, test_config(10, 10, time.Minute))
+	dial(t, addr, "alice")
+	dial(t, addr, "bob")
+	_, err := Dial(addr, "carol")
+	assert.EqualError(t, err, "game is full")
+}
+
+func TestPlayerLeaves(t *testing.T) {
+	addr := start_server(t, test_config(20, 20, time.Minute))
+	alice := dial(t, addr, "alice")
+	bob := dial(t, addr, "bob")
+
+	st, err := alice.Receive()
+	assert.NoError(t, err)
+	assert.Equal(t, 0, st.Tick)
+	bob.Close()
+
+	for !st.GameOver {
+		alice.SendInput(st.Tick, NO_TURN)
+		st, err = alice.Receive()
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+	assert.Equal(t, alice.Welcome.Player, st.Winner)
+	assert.True(t, st.Snakes[bob.Welcome.Player].Dead)
+}
+
+func TestSpectator(t *testing.T) {
+	addr := start_server(t, test_config(10, 10, time.Minute))
+	players := []*Client{dial(t, addr, "alice"), dial(t, addr, "bob")}
+
+	// Alice waits at a tick for the spectator to join, so that it joins
+	// in the middle of the game
+	const JOIN_TICK = 5
+	const TICKS = 30
+	at_join_tick := make(chan bool)
+	joined := make(chan bool)
+	results := make(chan []*State, len(players))
+	for _, c := range players {
+		go func(c *Client) {
+			states := []*State{}
+			for len(states) < TICKS {
+				st, err := c.Receive()
+				if err != nil {
+					break
+				}
+				states = append(states, st)
+				if st.GameOver {
+					break
+				}
+				if st.Tick == JOIN_TICK && c.Welcome.Player == 0 {
+					close(at_join_tick)
+					<-joined
+				}
+				script := TEST_SCRIPTS[c.Welcome.Player]
+				c.SendInput(st.Tick, script[st.Tick%len(script)])
+			}
+			results <- states
+		}(c)
+	}
+
+	<-at_join_tick
+	spectator, err := Spectate(addr, "carol")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer spectator.Close()
+	assert.Equal(t, SPECTATOR, spectator.Welcome.Player)
+	close(joined)
+
+	watched := []*State{}
+	for {
+		st, err := spectator.Receive()
+		if err != nil {
+			t.Fatal(err)
+		}
+		watched = append(watched, st)
+		if st.GameOver || st.Tick == TICKS-1 {
+			break
+		}
+	}
+	played := <-results
+
+	// The spectator sees the same states as the players from where it joined
+	assert.Equal(t, JOIN_TICK, watched[0].Tick)
+	assert.Equal(t, played[JOIN_TICK:JOIN_TICK+len(watched)], watched)
+}
+
+func TestInvalidWelcome(t *testing.T) {
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer l.Close()
+	go func() {
+		conn, err := l.Accept()
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+		new_decoder(conn).decode()
+		encode(conn, &Message{Type: MSG_WELCOME, Welcome: &Welcome{Player: snake.VERSUS_PLAYERS}})
+	}()
+	_, err = Dial(l.Addr().String(), "alice")
+	assert.EqualError(t, err, "welcome to invalid player 2")
+}
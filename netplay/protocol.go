@@ -0,0 +1,197 @@
+// Package netplay plays versus games over the network.
+//
+// The server owns the game, the clients only send the directions of their
+// snake and draw the state the server sends back.
+//
+// # Protocol
+//
+// Messages are JSON objects, one per line, over a TCP connection. Every
+// message has a "type" and the field of the same name, e.g.
+//
+//	{"type":"join","join":{"name":"alice"}}
+//
+// A client starts by sending a join message. The server answers with a
+// welcome message telling the client which snake it plays, or an error
+// message and closes the connection, e.g. when the game is full.
+//
+// Once every seat is taken the server starts a game and sends a state
+// message for tick 0. The clients answer every state message with an input
+// message for the same tick, with the direction to turn to, or -1 to keep
+// going. The server moves to the next tick after the tick interval, once it
+// has the inputs of every player for the tick. It waits at most the late input
+// grace for players that did not answer, and moves on without them, their
+// snake keeps going in its direction. An input that arrives late is played in
+// the next tick. Inputs are checked like keys pressed, turns that reverse the
+// snake are discarded.
+//
+// When a game is over the server sends its last state, with game_over set,
+// and starts the next game after a while. When a player leaves the game is
+// over and the other player wins.
+//
+// A client joining with spectate set watches the games without playing,
+// its welcome has player -1. It gets the state of the game being played
+// right away, and of every game when it starts. For the other ticks it gets
+// delta messages with the changes from the tick before, see Delta. A
+// spectator does not send anything after joining.
+//
+//	client                               server
+//	  join {name}                ->
+//	                             <-  welcome {player, width, height, rules, tick_ms}
+//	                             <-  state {game, tick: 0, ...}
+//	  input {tick: 0, dir}       ->
+//	                             <-  state {game, tick: 1, ...}
+//	  ...
+//
+//	spectator                            server
+//	  join {name, spectate: true} ->
+//	                             <-  welcome {player: -1, ...}
+//	                             <-  state {game, tick: 12, ...}
+//	                             <-  delta {game, tick: 13, ...}
+//	  ...
+package netplay
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"slices"
+
+	"github.com/redwookcreek/snake/snake"
+)
+
+// Types of the messages
+const (
+	MSG_JOIN    = "join"
+	MSG_WELCOME = "welcome"
+	MSG_INPUT   = "input"
+	MSG_STATE   = "state"
+	MSG_DELTA   = "delta"
+	MSG_ERROR   = "error"
+)
+
+// Direction of an input that does not turn the snake
+const NO_TURN = -1
+
+// Player of a client that only watches
+const SPECTATOR = -1
+
+// Longest line of a message
+const MAX_MESSAGE_SIZE = 1 << 20
+
+type Message struct {
+	Type    string   `json:"type"`
+	Join    *Join    `json:"join,omitempty"`
+	Welcome *Welcome `json:"welcome,omitempty"`
+	Input   *Input   `json:"input,omitempty"`
+	State   *State   `json:"state,omitempty"`
+	Delta   *Delta   `json:"delta,omitempty"`
+	Error   string   `json:"error,omitempty"`
+}
+
+// First message of a client
+type Join struct {
+	Name string `json:"name"`
+
+	// Watch the games instead of playing
+	Spectate bool `json:"spectate,omitempty"`
+}
+
+// Answer to a join, tells the client about the game it joined
+type Welcome struct {
+	// Index of the client's snake in State.Snakes, SPECTATOR
+	// for a client that only watches
+	Player int `json:"player"`
+
+	Width  int         `json:"width"`
+	Height int         `json:"height"`
+	Rules  snake.Rules `json:"rules"`
+
+	// Milliseconds between two ticks
+	TickMillis int `json:"tick_ms"`
+}
+
+// Direction of a player's snake, answering the state of a tick
+type Input struct {
+	Tick int `json:"tick"`
+
+	// One of the snake directions, or NO_TURN
+	Dir int `json:"dir"`
+}
+
+// State of the game at a tick
+type State struct {
+	// Number of the game played on the server, starting from 1
+	Game int `json:"game"`
+	Tick int `json:"tick"`
+
+	Snakes   []snake.VersusSnake `json:"snakes"`
+	Apple    snake.Point         `json:"apple"`
+	GameOver bool                `json:"game_over"`
+
+	// Index of the snake that won, -1 for a draw
+	Winner int `json:"winner"`
+}
+
+// Returns the state of a versus game at a tick
+func NewState(game, tick int, vs *snake.VersusState) *State {
+	snakes := make([]snake.VersusSnake, len(vs.Snakes))
+	for i, s := range vs.Snakes {
+		// the game goes on while the state is sent
+		s.SnakeBody = slices.Clone(s.SnakeBody)
+		snakes[i] = s
+	}
+	return &State{game, tick, snakes, vs.Board.Apple, vs.GameOver, vs.Winner}
+}
+
+// Copy the state into a versus game, so that it can be drawn
+func (st *State) Apply(vs *snake.VersusState) {
+	copy(vs.Snakes[:], st.Snakes)
+	vs.Board.Apple = st.Apple
+	vs.GameOver = st.GameOver
+	vs.Winner = st.Winner
+}
+
+// Reads messages from a connection
+type decoder struct {
+	scanner *bufio.Scanner
+}
+
+func new_decoder(r io.Reader) *decoder {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 4096), MAX_MESSAGE_SIZE)
+	return &decoder{scanner}
+}
+
+func (d *decoder) decode() (*Message, error) {
+	if !d.scanner.Scan() {
+		if err := d.scanner.Err(); err != nil {
+			return nil, err
+		}
+		return nil, io.EOF
+	}
+	msg := &Message{}
+	if err := json.Unmarshal(d.scanner.Bytes(), msg); err != nil {
+		return nil, fmt.Errorf("bad message: %w", err)
+	}
+	return msg, nil
+}
+
+// Returns the message as one line
+func marshal(msg *Message) ([]byte, error) {
+	data, err := json.Marshal(msg)
+	if err != nil {
+		return nil, fmt.Errorf("cannot encode %s message: %w", msg.Type, err)
+	}
+	return append(data, '\n'), nil
+}
+
+// Writes a message as one line
+func encode(w io.Writer, msg *Message) error {
+	line, err := marshal(msg)
+	if err != nil {
+		return err
+	}
+	_, err = w.Write(line)
+	return err
+}
@@ -0,0 +1,519 @@
+package netplay
+
+import (
+	"errors"
+	"log"
+	"net"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/redwookcreek/snake/snake"
+)
+
+const (
+	// Messages buffered for a client, a client further behind is dropped
+	SEND_BUFFER_SIZE = 64
+
+	// Time a client has to send its join message
+	JOIN_TIMEOUT = 10 * time.Second
+
+	// Time a message has to be written to a client
+	WRITE_TIMEOUT = 5 * time.Second
+
+	// Most clients watching at the same time
+	MAX_SPECTATORS = 32
+)
+
+type ServerConfig struct {
+	// Size of the board
+	Width  int
+	Height int
+
+	Rules snake.Rules
+
+	// Time between two ticks
+	TickInterval time.Duration
+
+	// Longest the server waits after a tick for the inputs of every player
+	LateInputGrace time.Duration
+
+	// Pause between the end of a game and the start of the next one
+	RestartDelay time.Duration
+
+	// Seed of the first game, the next games use the seeds following it
+	Seed uint64
+}
+
+var DEFAULT_SERVER_CONFIG = ServerConfig{
+	Width:          20,
+	Height:         20,
+	TickInterval:   snake.DEFAULT_SPEED.Interval,
+	LateInputGrace: 100 * time.Millisecond,
+	RestartDelay:   3 * time.Second,
+	Seed:           1,
+}
+
+// A connected client
+type client struct {
+	conn net.Conn
+	name string
+
+	// Lines to write to the client, closed when the client is dropped
+	send chan []byte
+}
+
+// A player of the game being played
+type seat struct {
+	*client
+
+	// Turns received, one is played every tick
+	input snake.InputQueue
+
+	// Latest tick the player sent an input for
+	acked int
+}
+
+// Plays versus games between the clients connected to it,
+// see the package doc for the protocol
+type Server struct {
+	Config ServerConfig
+
+	mu         sync.Mutex
+	seats      [snake.VERSUS_PLAYERS]*seat
+	spectators []*client
+	state      *snake.VersusState
+	games      int
+	tick       int
+	listener   net.Listener
+	closed     bool
+
+	// State sent last, spectators get the changes from it
+	last *State
+
+	// Signaled when a player joins, leaves or sends an input
+	changed chan struct{}
+	done    chan struct{}
+}
+
+func NewServer(config ServerConfig) *Server {
+	return &Server{
+		Config:  config,
+		changed: make(chan struct{}, 1),
+		done:    make(chan struct{}),
+	}
+}
+
+// Accept clients and play games until Close is called
+func (s *Server) Serve(l net.Listener) error {
+	s.mu.Lock()
+	if s.closed {
+		s.mu.Unlock()
+		return net.ErrClosed
+	}
+	s.listener = l
+	s.mu.Unlock()
+
+	go s.run()
+	for {
+		conn, err := l.Accept()
+		if err != nil {
+			s.mu.Lock()
+			closed := s.closed
+			s.mu.Unlock()
+			if closed {
+				return nil
+			}
+			return err
+		}
+		go s.handle(conn)
+	}
+}
+
+// Stop accepting clients and drop the ones connected
+func (s *Server) Close() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.closed {
+		return nil
+	}
+	s.closed = true
+	close(s.done)
+	for _, p := range s.seats {
+		if p != nil {
+			p.conn.Close()
+		}
+	}
+	for _, c := range s.spectators {
+		c.conn.Close()
+	}
+	if s.listener != nil {
+		return s.listener.Close()
+	}
+	return nil
+}
+
+func (s *Server) signal() {
+	select {
+	case s.changed <- struct{}{}:
+	default:
+	}
+}
+
+// Talk to a client until it leaves
+func (s *Server) handle(conn net.Conn) {
+	defer conn.Close()
+	dec := new_decoder(conn)
+
+	conn.SetReadDeadline(time.Now().Add(JOIN_TIMEOUT))
+	msg, err := dec.decode()
+	if err != nil {
+		return
+	}
+	if msg.Type != MSG_JOIN || msg.Join == nil {
+		write_error(conn, "expected a join message")
+		return
+	}
+	conn.SetReadDeadline(time.Time{})
+
+	c := &client{conn, msg.Join.Name, make(chan []byte, SEND_BUFFER_SIZE)}
+	if msg.Join.Spectate {
+		s.watch(c, dec)
+		return
+	}
+	player, err := s.sit(c)
+	if err != nil {
+		write_error(conn, err.Error())
+		return
+	}
+	go c.write_loop()
+	defer s.leave(player, c)
+
+	for {
+		msg, err := dec.decode()
+		if err != nil {
+			return
+		}
+		if msg.Type == MSG_INPUT && msg.Input != nil {
+			s.receive_input(player, *msg.Input)
+		}
+	}
+}
+
+// Give the client a free seat and welcome it
+func (s *Server) sit(c *client) (int, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.closed {
+		return 0, net.ErrClosed
+	}
+	for i, p := range s.seats {
+		if p != nil {
+			continue
+		}
+		s.seats[i] = &seat{c, snake.InputQueue{}, -1}
+		welcome := &Welcome{
+			i,
+			s.Config.Width,
+			s.Config.Height,
+			s.Config.Rules,
+			int(s.Config.TickInterval / time.Millisecond),
+		}
+		s.send(s.seats[i], &Message{Type: MSG_WELCOME, Welcome: welcome})
+		log.Printf("%s joined as player %d", c.name, i+1)
+		s.signal()
+		return i, nil
+	}
+	return 0, errors.New("game is full")
+}
+
+// Send the games to a spectator until it leaves
+func (s *Server) watch(c *client, dec *decoder) {
+	if err := s.add_spectator(c); err != nil {
+		write_error(c.conn, err.Error())
+		return
+	}
+	go c.write_loop()
+	defer s.remove_spectator(c)
+	// Nothing is expected from a spectator, read to know when it leaves
+	for {
+		if _, err := dec.decode(); err != nil {
+			return
+		}
+	}
+}
+
+// Welcome a spectator and send it the game being played
+func (s *Server) add_spectator(c *client) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.closed {
+		return net.ErrClosed
+	}
+	if len(s.spectators) >= MAX_SPECTATORS {
+		return errors.New("too many spectators")
+	}
+	s.spectators = append(s.spectators, c)
+	welcome := &Welcome{
+		SPECTATOR,
+		s.Config.Width,
+		s.Config.Height,
+		s.Config.Rules,
+		int(s.Config.TickInterval / time.Millisecond),
+	}
+	s.queue(c, &Message{Type: MSG_WELCOME, Welcome: welcome})
+	if s.last != nil {
+		s.queue(c, &Message{Type: MSG_STATE, State: s.last})
+	}
+	log.Printf("%s is watching", c.name)
+	return nil
+}
+
+func (s *Server) remove_spectator(c *client) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for i, other := range s.spectators {
+		if other == c {
+			s.spectators = slices.Delete(s.spectators, i, i+1)
+			close(c.send)
+			c.conn.Close()
+			return
+		}
+	}
+}
+
+// Free the seat of a player that left, the game being played is lost
+func (s *Server) leave(player int, c *client) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	p := s.seats[player]
+	if p == nil || p.client != c {
+		// already dropped
+		return
+	}
+	s.drop(player)
+	log.Printf("%s left", p.name)
+	if s.state != nil {
+		s.state.Resign(player)
+	}
+	s.signal()
+}
+
+// Forget a player and stop writing to it, must hold the lock
+func (s *Server) drop(player int) {
+	p := s.seats[player]
+	s.seats[player] = nil
+	close(p.send)
+	p.conn.Close()
+}
+
+func (s *Server) receive_input(player int, input Input) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	p := s.seats[player]
+	if p == nil || s.state == nil {
+		return
+	}
+	if input.Dir != NO_TURN && !slices.Contains(snake.DIRECTIONS[:], input.Dir) {
+		// not an answer either
+		return
+	}
+	if input.Dir != NO_TURN {
+		p.input.Push(input.Dir, s.state.Snakes[player].Direction)
+	}
+	// An input for a tick not played yet is not an answer for it
+	p.acked = max(p.acked, min(input.Tick, s.tick))
+	s.signal()
+}
+
+// Queue a message for a player, must hold the lock
+func (s *Server) send(p *seat, msg *Message) {
+	if !s.queue(p.client, msg) {
+		// Too far behind, let it go
+		for i, other := range s.seats {
+			if other == p {
+				s.drop(i)
+				if s.state != nil {
+					s.state.Resign(i)
+				}
+			}
+		}
+		s.signal()
+	}
+}
+
+// Queue a message for a client, returns false if the client
+// is too far behind
+func (s *Server) queue(c *client, msg *Message) bool {
+	line, err := marshal(msg)
+	if err != nil {
+		log.Print(err)
+		return true
+	}
+	select {
+	case c.send <- line:
+		return true
+	default:
+		return false
+	}
+}
+
+// Send the state of the current tick to every player, and its changes
+// to the spectators, must hold the lock
+func (s *Server) broadcast(state *State) {
+	for _, p := range s.seats {
+		if p != nil {
+			s.send(p, &Message{Type: MSG_STATE, State: state})
+		}
+	}
+	spectator_msg := &Message{Type: MSG_STATE, State: state}
+	if s.last != nil && s.last.Game == state.Game {
+		spectator_msg = &Message{Type: MSG_DELTA, Delta: NewDelta(s.last, state)}
+	}
+	s.last = state
+	spectators := s.spectators[:0]
+	for _, c := range s.spectators {
+		if s.queue(c, spectator_msg) {
+			spectators = append(spectators, c)
+		} else {
+			close(c.send)
+			c.conn.Close()
+		}
+	}
+	s.spectators = spectators
+}
+
+func (c *client) write_loop() {
+	for line := range c.send {
+		c.conn.SetWriteDeadline(time.Now().Add(WRITE_TIMEOUT))
+		if _, err := c.conn.Write(line); err != nil {
+			c.conn.Close()
+			// drain so that the server never blocks on this client
+			for range c.send {
+			}
+			return
+		}
+	}
+}
+
+func write_error(conn net.Conn, text string) {
+	conn.SetWriteDeadline(time.Now().Add(WRITE_TIMEOUT))
+	encode(conn, &Message{Type: MSG_ERROR, Error: text})
+}
+
+// Play games as long as the server is open
+func (s *Server) run() {
+	for s.wait_for_players() {
+		s.play_game()
+		select {
+		case <-s.done:
+			return
+		case <-time.After(s.Config.RestartDelay):
+		}
+	}
+}
+
+// Wait until every seat is taken, returns false if the server was closed
+func (s *Server) wait_for_players() bool {
+	for {
+		s.mu.Lock()
+		full := true
+		for _, p := range s.seats {
+			full = full && p != nil
+		}
+		s.mu.Unlock()
+		if full {
+			return true
+		}
+		select {
+		case <-s.done:
+			return false
+		case <-s.changed:
+		}
+	}
+}
+
+func (s *Server) play_game() {
+	s.mu.Lock()
+	s.games += 1
+	s.tick = 0
+	s.state = snake.CreateVersus(
+		s.Config.Height,
+		s.Config.Width,
+		snake.WithRules(s.Config.Rules),
+		snake.WithSeed(s.Config.Seed+uint64(s.games-1)))
+	for _, p := range s.seats {
+		if p != nil {
+			p.input.Clear()
+			p.acked = -1
+		}
+	}
+	s.mu.Unlock()
+
+	for {
+		s.mu.Lock()
+		state := NewState(s.games, s.tick, s.state)
+		s.broadcast(state)
+		s.mu.Unlock()
+		if state.GameOver || !s.wait_for_inputs() {
+			break
+		}
+		s.mu.Lock()
+		s.step()
+		s.mu.Unlock()
+	}
+
+	s.mu.Lock()
+	s.state = nil
+	s.mu.Unlock()
+}
+
+// Wait for the tick interval, then for the players that did not answer
+// the current tick yet, at most the late input grace.
+// Returns false if the server was closed
+func (s *Server) wait_for_inputs() bool {
+	select {
+	case <-s.done:
+		return false
+	case <-time.After(s.Config.TickInterval):
+	}
+	grace := time.After(s.Config.LateInputGrace)
+	for !s.all_answered() {
+		select {
+		case <-s.done:
+			return false
+		case <-grace:
+			return true
+		case <-s.changed:
+		}
+	}
+	return true
+}
+
+// True if every player sent its input for the current tick,
+// or the game is already over
+func (s *Server) all_answered() bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.state.GameOver {
+		return true
+	}
+	for _, p := range s.seats {
+		if p != nil && p.acked < s.tick {
+			return false
+		}
+	}
+	return true
+}
+
+// Play one turn of every player and advance the game, must hold the lock
+func (s *Server) step() {
+	for i, p := range s.seats {
+		if p != nil {
+			dir := s.state.Snakes[i].Direction
+			s.state.UpdateDirection(i, p.input.Pop(dir))
+		}
+	}
+	s.state.Tick()
+	s.tick += 1
+}
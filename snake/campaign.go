@@ -0,0 +1,106 @@
+package snake
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+// Plays levels one after another, reaching the goal score
+// of a level moves on to the next one
+type Campaign struct {
+	Levels []*Level
+
+	// Index of the level being played
+	Current int
+
+	// Score of the completed levels
+	TotalScore int
+}
+
+// Start a campaign at the level with index start
+func NewCampaign(levels []*Level, start int) (*Campaign, error) {
+	if start < 0 || start >= len(levels) {
+		return nil, fmt.Errorf("campaign has %d levels, cannot start at level %d", len(levels), start+1)
+	}
+	return &Campaign{levels, start, 0}, nil
+}
+
+func (c *Campaign) Level() *Level {
+	return c.Levels[c.Current]
+}
+
+// Score to reach to complete the current level
+func (c *Campaign) Goal() int {
+	return c.Level().GoalScore()
+}
+
+// True if the game has reached the goal of the current level
+func (c *Campaign) LevelComplete(ss *SnakeState) bool {
+	return !ss.GameOver && ss.Score >= c.Goal()
+}
+
+// True if the current level is the last one
+func (c *Campaign) LastLevel() bool {
+	return c.Current == len(c.Levels)-1
+}
+
+// Complete the current level with its score and move to the next one.
+// Returns false if there is no next level, the campaign is finished
+func (c *Campaign) Advance(score int) bool {
+	c.TotalScore += score
+	if c.LastLevel() {
+		return false
+	}
+	c.Current += 1
+	return true
+}
+
+// Furthest level reached in campaigns, kept between runs
+type CampaignProgress struct {
+	// Index of the furthest level that can be started
+	Unlocked int `json:"unlocked"`
+}
+
+// Unlock the level with index level, returns true if it was locked
+func (p *CampaignProgress) Unlock(level int) bool {
+	if level <= p.Unlocked {
+		return false
+	}
+	p.Unlocked = level
+	return true
+}
+
+// Returns the default file for the campaign progress
+func DefaultProgressPath() (string, error) {
+	dir, err := ConfigDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "campaign.json"), nil
+}
+
+// Load the campaign progress, no file means nothing unlocked yet
+func LoadProgress(path string) (CampaignProgress, error) {
+	progress := CampaignProgress{}
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return progress, nil
+	} else if err != nil {
+		return progress, err
+	}
+	if err := json.Unmarshal(data, &progress); err != nil {
+		return CampaignProgress{}, fmt.Errorf("invalid campaign progress %s: %w", path, err)
+	}
+	return progress, nil
+}
+
+func SaveProgress(path string, progress CampaignProgress) error {
+	data, err := json.Marshal(progress)
+	if err != nil {
+		return err
+	}
+	return WriteFileAtomic(path, data, 0644)
+}
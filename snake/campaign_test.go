@@ -0,0 +1,56 @@
+package snake
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCampaign(t *testing.T) {
+	levels := Levels()[:2]
+	_, err := NewCampaign(levels, 2)
+	assert.Error(t, err)
+
+	c, err := NewCampaign(levels, 0)
+	assert.NoError(t, err)
+	assert.Equal(t, levels[0], c.Level())
+	assert.Equal(t, levels[0].Goal, c.Goal())
+
+	ss := CreateSnake(0, 0, WithLevel(c.Level()))
+	assert.False(t, c.LevelComplete(ss))
+	ss.Score = c.Goal()
+	assert.True(t, c.LevelComplete(ss))
+	// A lost game does not complete the level
+	ss.GameOver = true
+	assert.False(t, c.LevelComplete(ss))
+
+	assert.False(t, c.LastLevel())
+	assert.True(t, c.Advance(12))
+	assert.Equal(t, levels[1], c.Level())
+	assert.True(t, c.LastLevel())
+	assert.False(t, c.Advance(15))
+	// Score of all levels is kept
+	assert.Equal(t, 27, c.TotalScore)
+
+	// Levels without goal
+	c, _ = NewCampaign([]*Level{{Name: "no goal"}}, 0)
+	assert.Equal(t, DEFAULT_LEVEL_GOAL, c.Goal())
+}
+
+func TestCampaignProgress(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "campaign.json")
+
+	// Nothing saved yet
+	progress, err := LoadProgress(path)
+	assert.NoError(t, err)
+	assert.Equal(t, 0, progress.Unlocked)
+
+	assert.True(t, progress.Unlock(2))
+	assert.False(t, progress.Unlock(1))
+	assert.NoError(t, SaveProgress(path, progress))
+
+	loaded, err := LoadProgress(path)
+	assert.NoError(t, err)
+	assert.Equal(t, 2, loaded.Unlocked)
+}
@@ -0,0 +1,139 @@
+package snake
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+const (
+	// Smallest and largest side of a board, in cells
+	MIN_BOARD_SIZE = 3
+	MAX_BOARD_SIZE = 200
+
+	// Smallest side of a board with a boarder, which leaves a 2x2 square
+	// inside for the snake to turn around in. A board that wraps around
+	// has no boarder
+	MIN_WALLED_BOARD_SIZE = MIN_BOARD_SIZE + 1
+
+	// Smallest side of the window, in pixels
+	MIN_WINDOW_SIZE = 100
+)
+
+// Settings of the game, read from the config file and the command line
+type Config struct {
+	// Size of the board, unless a level is played
+	Width  int `json:"width"`
+	Height int `json:"height"`
+
+	// Size of the window, in pixels
+	WindowWidth  int  `json:"window_width"`
+	WindowHeight int  `json:"window_height"`
+	Fullscreen   bool `json:"fullscreen"`
+
+	// Name of one of the DIFFICULTIES
+	Speed       string `json:"speed"`
+	Progressive bool   `json:"progressive"`
+
+	Wrap  bool `json:"wrap"`
+	Lives int  `json:"lives"`
+	Items bool `json:"items"`
+	Timed bool `json:"timed"`
+
+	// Seed of the first game, a random one if 0
+	Seed uint64 `json:"seed"`
+
+	// Bundled level name or level file, the board size comes from the level
+	Level string `json:"level,omitempty"`
+
+	// Key names bound to each action by action name, e.g. "up": ["W"].
+	// The actions not bound keep their default keys
+	Bindings map[string][]string `json:"bindings,omitempty"`
+}
+
+// The window fits 32 pixel cells and the HUD above them
+var DEFAULT_CONFIG = Config{
+	Width:        20,
+	Height:       20,
+	WindowWidth:  640,
+	WindowHeight: 710,
+	Speed:        "normal",
+	Lives:        1,
+}
+
+// Returns an error telling everything wrong with the config, nil if none
+func (c *Config) Validate() error {
+	errs := []error{}
+	check_size := func(what string, size, low, high int) {
+		if size < low {
+			errs = append(errs, fmt.Errorf("%s is %d, should be at least %d", what, size, low))
+		} else if high > 0 && size > high {
+			errs = append(errs, fmt.Errorf("%s is %d, should be at most %d", what, size, high))
+		}
+	}
+	min_board_size := MIN_BOARD_SIZE
+	if !c.Wrap {
+		min_board_size = MIN_WALLED_BOARD_SIZE
+	}
+	check_size("board width", c.Width, min_board_size, MAX_BOARD_SIZE)
+	check_size("board height", c.Height, min_board_size, MAX_BOARD_SIZE)
+	check_size("window width", c.WindowWidth, MIN_WINDOW_SIZE, 0)
+	check_size("window height", c.WindowHeight, MIN_WINDOW_SIZE, 0)
+	check_size("lives", c.Lives, 1, 0)
+	if _, err := FindDifficulty(c.Speed); err != nil {
+		errs = append(errs, err)
+	}
+	if c.Level != "" {
+		if err := CheckLevelRules(c.Rules()); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+func (c *Config) Rules() Rules {
+	return Rules{Wrap: c.Wrap, Lives: c.Lives, Items: c.Items, TimedApples: c.Timed}
+}
+
+// Returns the speed of the config, which must be valid
+func (c *Config) GameSpeed() Speed {
+	d, _ := FindDifficulty(c.Speed)
+	return Speed{Interval: d.Interval, Progressive: c.Progressive}
+}
+
+// Returns the default config file
+func DefaultConfigPath() (string, error) {
+	dir, err := ConfigDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "config.json"), nil
+}
+
+// Load a config file, the settings not in the file are the default ones.
+// The config is not validated
+func LoadConfig(path string) (Config, error) {
+	config := DEFAULT_CONFIG
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return config, err
+	}
+	dec := json.NewDecoder(bytes.NewReader(data))
+	// catch misspelled settings
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(&config); err != nil {
+		return DEFAULT_CONFIG, fmt.Errorf("invalid config %s: %w", path, err)
+	}
+	return config, nil
+}
+
+func SaveConfig(path string, config Config) error {
+	data, err := json.MarshalIndent(config, "", "  ")
+	if err != nil {
+		return err
+	}
+	return WriteFileAtomic(path, data, 0644)
+}
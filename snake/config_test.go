@@ -0,0 +1,94 @@
+package snake
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestConfigValidate(t *testing.T) {
+	c := DEFAULT_CONFIG
+	assert.NoError(t, c.Validate())
+
+	c.Height = 2
+	c.Width = 1000
+	c.Speed = "ludicrous"
+	err := c.Validate()
+	assert.ErrorContains(t, err, "board height is 2, should be at least 4")
+	assert.ErrorContains(t, err, "board width is 1000, should be at most 200")
+	assert.ErrorContains(t, err, `unknown difficulty "ludicrous"`)
+
+	// A boarder leaves no room to turn on a 3 cell side
+	c = DEFAULT_CONFIG
+	c.Width = 3
+	c.Height = 4
+	assert.ErrorContains(t, c.Validate(), "board width is 3, should be at least 4")
+	c.Width = 4
+	assert.NoError(t, c.Validate())
+	c.Width = 3
+	c.Height = 3
+	c.Wrap = true
+	assert.NoError(t, c.Validate())
+	c.Height = 2
+	assert.ErrorContains(t, c.Validate(), "board height is 2, should be at least 3")
+
+	// The boarder of a level does not wrap around
+	c = DEFAULT_CONFIG
+	c.Level = "maze"
+	assert.NoError(t, c.Validate())
+	c.Wrap = true
+	assert.ErrorContains(t, c.Validate(), "levels cannot be played on a board that wraps around")
+
+	c = DEFAULT_CONFIG
+	c.WindowWidth = 50
+	c.Lives = 0
+	err = c.Validate()
+	assert.ErrorContains(t, err, "window width is 50")
+	assert.ErrorContains(t, err, "lives is 0")
+}
+
+func TestConfigRulesAndSpeed(t *testing.T) {
+	c := DEFAULT_CONFIG
+	c.Wrap = true
+	c.Lives = 3
+	c.Timed = true
+	c.Speed = "hard"
+	c.Progressive = true
+	assert.Equal(t, Rules{Wrap: true, Lives: 3, TimedApples: true}, c.Rules())
+	assert.Equal(t, Speed{Interval: DIFFICULTIES[2].Interval, Progressive: true}, c.GameSpeed())
+}
+
+func TestLoadConfig(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "config.json")
+
+	_, err := LoadConfig(path)
+	assert.ErrorIs(t, err, os.ErrNotExist)
+
+	// Settings not in the file keep their default
+	assert.NoError(t, os.WriteFile(path, []byte(`{"width": 30, "wrap": true}`), 0644))
+	c, err := LoadConfig(path)
+	assert.NoError(t, err)
+	expected := DEFAULT_CONFIG
+	expected.Width = 30
+	expected.Wrap = true
+	assert.Equal(t, expected, c)
+
+	assert.NoError(t, os.WriteFile(path, []byte(`{"widht": 30}`), 0644))
+	_, err = LoadConfig(path)
+	assert.ErrorContains(t, err, "widht")
+}
+
+func TestSaveConfig(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.json")
+	c := DEFAULT_CONFIG
+	c.Level = "maze"
+	c.Lives = 2
+	c.Bindings = map[string][]string{"up": {"W", "ArrowUp"}}
+	assert.NoError(t, SaveConfig(path, c))
+	loaded, err := LoadConfig(path)
+	assert.NoError(t, err)
+	assert.Equal(t, c, loaded)
+}
@@ -0,0 +1,103 @@
+package snake
+
+// Controls the snake instead of the keyboard
+type Controller interface {
+	// Returns the direction to turn the snake to before the next tick
+	NextDirection(view SnakeView) int
+}
+
+// Read-only view of a SnakeState given to controllers
+type SnakeView struct {
+	ss *SnakeState
+}
+
+func (ss *SnakeState) View() SnakeView {
+	return SnakeView{ss}
+}
+
+func (v SnakeView) Width() int {
+	return v.ss.Width
+}
+
+func (v SnakeView) Height() int {
+	return v.ss.Height
+}
+
+func (v SnakeView) Direction() int {
+	return v.ss.Direction
+}
+
+func (v SnakeView) Score() int {
+	return v.ss.Score
+}
+
+func (v SnakeView) HasApple() bool {
+	return v.ss.HasApple()
+}
+
+func (v SnakeView) Apple() Point {
+	return v.ss.Apple
+}
+
+// Number of cells of the snake
+func (v SnakeView) Len() int {
+	return len(v.ss.SnakeBody)
+}
+
+// Returns the i-th cell of the snake, 0 is the tail and Len()-1 the head
+func (v SnakeView) Body(i int) Point {
+	return v.ss.SnakeBody[i].Cord
+}
+
+func (v SnakeView) Head() Point {
+	return v.Body(v.Len() - 1)
+}
+
+// Returns the area the snake can move in, from min inclusive to max exclusive
+func (v SnakeView) PlayArea() (Point, Point) {
+	return v.ss.play_area()
+}
+
+// True if the board wraps around at the edges
+func (v SnakeView) Wrap() bool {
+	return v.ss.Rules.Wrap
+}
+
+// True if the board has walls inside the boarder
+func (v SnakeView) HasObstacles() bool {
+	return len(v.ss.obstacles) > 0
+}
+
+// Returns the cell next to p in the direction
+func (v SnakeView) Neighbor(p Point, dir int) Point {
+	next := p.Next(dir)
+	if v.ss.Rules.Wrap {
+		next = v.ss.wrap(next)
+	}
+	return next
+}
+
+// Number of moves from p1 to p2 on an empty board
+func (v SnakeView) Distance(p1, p2 Point) int {
+	dx, dy := p2.X-p1.X, p2.Y-p1.Y
+	if v.ss.Rules.Wrap {
+		// going the other way around might be shorter
+		lo, hi := v.ss.play_area()
+		dx = min(abs(dx), hi.X-lo.X-abs(dx))
+		dy = min(abs(dy), hi.Y-lo.Y-abs(dy))
+	}
+	return abs(dx) + abs(dy)
+}
+
+func abs(x int) int {
+	if x < 0 {
+		return -x
+	}
+	return x
+}
+
+// True if moving the head to p ends the game, the tail is
+// not counted if it moves away in the same tick
+func (v SnakeView) Blocked(p Point) bool {
+	return v.ss.snake_touched(p)
+}
@@ -0,0 +1,63 @@
+package snake
+
+// Things that happen in one tick of the game, several events
+// can happen in the same tick, e.g. eating the last apple wins the game
+type Event uint8
+
+const (
+	EVENT_ATE_APPLE Event = 1 << iota
+	EVENT_DIED
+	EVENT_WON
+	// Touched a wall or itself but had lives left
+	EVENT_LOST_LIFE
+)
+
+// True if all events in other happened
+func (e Event) Has(other Event) bool {
+	return e&other == other
+}
+
+// Drives a SnakeState without any window or input device,
+// so that bots, servers and tests can play the game
+type Engine struct {
+	State *SnakeState
+
+	// Number of ticks played
+	Ticks int
+}
+
+func NewEngine(ss *SnakeState) *Engine {
+	return &Engine{ss, 0}
+}
+
+// Turn the snake to dir and advance it one tick,
+// returns the events happened in the tick
+func (e *Engine) Step(dir int) Event {
+	ss := e.State
+	if ss.GameOver {
+		return 0
+	}
+	apples, lives := ss.Breakdown.Apples, ss.Lives
+	ss.UpdateDirection(dir)
+	ss.Tick()
+	e.Ticks += 1
+
+	var events Event
+	// items like golden apples add points too, only count the apples
+	if ss.Breakdown.Apples > apples {
+		events |= EVENT_ATE_APPLE
+	}
+	if ss.GameWon {
+		events |= EVENT_WON
+	} else if ss.GameOver {
+		events |= EVENT_DIED
+	} else if ss.Lives < lives {
+		events |= EVENT_LOST_LIFE
+	}
+	return events
+}
+
+// Let the controller turn the snake and advance it one tick
+func (e *Engine) StepController(c Controller) Event {
+	return e.Step(c.NextDirection(e.State.View()))
+}
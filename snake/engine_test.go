@@ -0,0 +1,65 @@
+package snake
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestEngineStep(t *testing.T) {
+	e := NewEngine(CreateSnake(10, 10))
+	e.State.Apple = Point{5, 6}
+
+	assert.Equal(t, EVENT_ATE_APPLE, e.Step(DOWN))
+	assert.Equal(t, 1, e.Ticks)
+	// The new apple is random, put it away from the way to the boarder
+	e.State.Apple = Point{8, 8}
+
+	// Run into the left boarder
+	for i := 0; i < 5; i++ {
+		events := e.Step(LEFT)
+		assert.False(t, events.Has(EVENT_ATE_APPLE))
+		// dies on the last step only
+		assert.Equal(t, i == 4, events.Has(EVENT_DIED), "step %d", i)
+		assert.False(t, events.Has(EVENT_WON))
+	}
+
+	// Nothing happens after the game is over
+	assert.Equal(t, Event(0), e.Step(LEFT))
+	assert.Equal(t, 6, e.Ticks)
+}
+
+func TestEngineStepWon(t *testing.T) {
+	ss := CreateSnake(4, 4)
+	ss.SnakeBody = []SnakePart{
+		make_tail(1, 1, BODY_PART_TAIL_RIGHT),
+		make_body(2, 1),
+		make_head(2, 2, BODY_PART_HEAD_DOWN)}
+	ss.Apple = Point{1, 2}
+	e := NewEngine(ss)
+
+	events := e.Step(LEFT)
+	assert.True(t, events.Has(EVENT_ATE_APPLE|EVENT_WON))
+	assert.False(t, events.Has(EVENT_DIED))
+}
+
+func TestEngineStepItem(t *testing.T) {
+	e := NewEngine(CreateSnake(10, 10, WithRules(Rules{Items: true})))
+	e.State.Apple = Point{8, 8}
+	e.State.Items = []Item{{ITEM_GOLDEN_APPLE, Point{5, 6}, 10}}
+
+	// A golden apple is not the apple
+	assert.Equal(t, Event(0), e.Step(DOWN))
+	assert.Equal(t, GOLDEN_APPLE_SCORE, e.State.Score)
+}
+
+// Plays games that circle around the center until the snake dies
+func BenchmarkEngine(b *testing.B) {
+	dirs := []int{LEFT, LEFT, UP, UP, RIGHT, RIGHT, RIGHT, DOWN, DOWN, DOWN}
+	for i := 0; i < b.N; i++ {
+		e := NewEngine(CreateSnake(20, 20, WithSeed(uint64(i))))
+		for !e.State.GameOver {
+			e.Step(dirs[e.Ticks%len(dirs)])
+		}
+	}
+}
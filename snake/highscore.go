@@ -0,0 +1,154 @@
+package snake
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+)
+
+// Number of scores kept for each board
+const MAX_HIGH_SCORES = 10
+
+// Longest name of a high score
+const MAX_NAME_LENGTH = 16
+
+type HighScore struct {
+	Name  string    `json:"name"`
+	Score int       `json:"score"`
+	Date  time.Time `json:"date"`
+}
+
+// Best scores of each board, scores are only compared between games
+// on the same board size and rules, see HighScoreKey
+type HighScores struct {
+	Boards map[string][]HighScore `json:"boards"`
+}
+
+// Returns the board a game's score is compared on,
+// e.g. "20x20 wrap lives=3"
+func HighScoreKey(ss *SnakeState) string {
+	parts := []string{fmt.Sprintf("%dx%d", ss.Width, ss.Height)}
+	if ss.Level != nil {
+		parts = append(parts, ss.Level.Name)
+	}
+	return strings.Join(append(parts, rules_key_parts(ss.Rules)...), " ")
+}
+
+// Returns the board the total score of campaigns is compared on,
+// e.g. "campaign lives=3"
+func CampaignHighScoreKey(rules Rules) string {
+	return strings.Join(append([]string{"campaign"}, rules_key_parts(rules)...), " ")
+}
+
+func rules_key_parts(rules Rules) []string {
+	parts := []string{}
+	if rules.Wrap {
+		parts = append(parts, "wrap")
+	}
+	if rules.Lives > 1 {
+		parts = append(parts, fmt.Sprintf("lives=%d", rules.Lives))
+	}
+	if rules.Items {
+		parts = append(parts, "items")
+	}
+	if rules.TimedApples {
+		parts = append(parts, "timed")
+	}
+	return parts
+}
+
+// Returns the best scores of a board, best first
+func (hs *HighScores) Top(key string) []HighScore {
+	return hs.Boards[key]
+}
+
+// True if the score makes it to the table of the board
+func (hs *HighScores) Qualifies(key string, score int) bool {
+	top := hs.Boards[key]
+	return score > 0 && (len(top) < MAX_HIGH_SCORES || score > top[len(top)-1].Score)
+}
+
+// Add a score to the table of the board, returns its rank from 0,
+// or -1 if it did not make it to the table
+func (hs *HighScores) Add(key string, score HighScore) int {
+	if !hs.Qualifies(key, score.Score) {
+		return -1
+	}
+	if hs.Boards == nil {
+		hs.Boards = map[string][]HighScore{}
+	}
+	top := hs.Boards[key]
+	// Below the scores it ties with, they got there first
+	rank := len(top)
+	for i, s := range top {
+		if score.Score > s.Score {
+			rank = i
+			break
+		}
+	}
+	top = slices.Insert(top, rank, score)
+	hs.Boards[key] = top[:min(len(top), MAX_HIGH_SCORES)]
+	return rank
+}
+
+// Returns the default file for the high scores
+func DefaultHighScorePath() (string, error) {
+	dir, err := ConfigDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "highscores.json"), nil
+}
+
+// Load the high scores, no file means no scores yet.
+// A damaged file does not lose the scores that can still be read, the
+// boards that cannot are dropped and an error tells about them. A file
+// that cannot be read at all is kept next to it with a .bad suffix
+func LoadHighScores(path string) (HighScores, error) {
+	hs := HighScores{map[string][]HighScore{}}
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return hs, nil
+	} else if err != nil {
+		return hs, err
+	}
+
+	var file struct {
+		Boards map[string]json.RawMessage `json:"boards"`
+	}
+	if err := json.Unmarshal(data, &file); err != nil {
+		if rename_err := os.Rename(path, path+".bad"); rename_err != nil {
+			return hs, fmt.Errorf("invalid high scores %s, could not move it to %s.bad: %w: %w", path, path, err, rename_err)
+		}
+		return hs, fmt.Errorf("invalid high scores %s, moved to %s.bad: %w", path, path, err)
+	}
+	bad := []string{}
+	for key, raw := range file.Boards {
+		var top []HighScore
+		if err := json.Unmarshal(raw, &top); err != nil {
+			bad = append(bad, key)
+			continue
+		}
+		for _, s := range top {
+			hs.Add(key, s)
+		}
+	}
+	if len(bad) > 0 {
+		slices.Sort(bad)
+		return hs, fmt.Errorf("invalid high scores %s for %s", path, strings.Join(bad, ", "))
+	}
+	return hs, nil
+}
+
+func SaveHighScores(path string, hs HighScores) error {
+	data, err := json.MarshalIndent(hs, "", "  ")
+	if err != nil {
+		return err
+	}
+	return WriteFileAtomic(path, data, 0644)
+}
@@ -0,0 +1,101 @@
+package snake
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestHighScoreKey(t *testing.T) {
+	assert.Equal(t, "20x10", HighScoreKey(CreateSnake(10, 20)))
+	ss := CreateSnake(20, 20, WithRules(Rules{Wrap: true, Lives: 3, Items: true, TimedApples: true}))
+	assert.Equal(t, "20x20 wrap lives=3 items timed", HighScoreKey(ss))
+	ss = CreateSnake(0, 0, WithLevel(&Level{Name: "bars", Width: 20, Height: 20}))
+	assert.Equal(t, "20x20 bars", HighScoreKey(ss))
+	assert.Equal(t, "campaign", CampaignHighScoreKey(Rules{}))
+	assert.Equal(t, "campaign lives=3 items", CampaignHighScoreKey(Rules{Lives: 3, Items: true}))
+}
+
+func TestHighScores(t *testing.T) {
+	hs := HighScores{}
+	assert.False(t, hs.Qualifies("a", 0))
+	assert.True(t, hs.Qualifies("a", 1))
+
+	for i := 1; i <= MAX_HIGH_SCORES; i++ {
+		assert.Equal(t, 0, hs.Add("a", HighScore{Name: "p", Score: i * 10}))
+	}
+	assert.Len(t, hs.Top("a"), MAX_HIGH_SCORES)
+	assert.Equal(t, 100, hs.Top("a")[0].Score)
+
+	// Full table, only better scores get in
+	assert.False(t, hs.Qualifies("a", 10))
+	assert.Equal(t, -1, hs.Add("a", HighScore{Name: "q", Score: 10}))
+	// Ties go below
+	assert.Equal(t, 2, hs.Add("a", HighScore{Name: "q", Score: 90}))
+	assert.Equal(t, "p", hs.Top("a")[1].Name)
+	assert.Len(t, hs.Top("a"), MAX_HIGH_SCORES)
+	assert.Equal(t, 20, hs.Top("a")[MAX_HIGH_SCORES-1].Score)
+
+	// Other boards are kept apart
+	assert.Empty(t, hs.Top("b"))
+	assert.True(t, hs.Qualifies("b", 1))
+}
+
+func TestSaveHighScores(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "highscores.json")
+
+	// Nothing saved yet
+	hs, err := LoadHighScores(path)
+	assert.NoError(t, err)
+	assert.Empty(t, hs.Top("a"))
+
+	hs.Add("a", HighScore{Name: "p", Score: 3})
+	hs.Add("b", HighScore{Name: "q", Score: 5})
+	assert.NoError(t, SaveHighScores(path, hs))
+	loaded, err := LoadHighScores(path)
+	assert.NoError(t, err)
+	assert.Equal(t, hs.Top("a"), loaded.Top("a"))
+	assert.Equal(t, hs.Top("b"), loaded.Top("b"))
+
+	// No temporary file left
+	files, _ := os.ReadDir(dir)
+	assert.Len(t, files, 1)
+}
+
+func TestLoadDamagedHighScores(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "highscores.json")
+
+	// A damaged board is dropped, the others are kept
+	os.WriteFile(path, []byte(`{"boards": {
+		"a": [{"name": "p", "score": 3}, {"name": "q", "score": 7}],
+		"b": "garbage",
+		"c": [{"name": "r", "score": -1}]}}`), 0644)
+	hs, err := LoadHighScores(path)
+	assert.EqualError(t, err, "invalid high scores "+path+" for b")
+	assert.Equal(t, []HighScore{{Name: "q", Score: 7}, {Name: "p", Score: 3}}, hs.Top("a"))
+	assert.Empty(t, hs.Top("b"))
+	assert.Empty(t, hs.Top("c"))
+
+	// A file that cannot be read is put aside
+	os.WriteFile(path, []byte(`{"boards": {"a": [`), 0644)
+	hs, err = LoadHighScores(path)
+	assert.Error(t, err)
+	assert.Empty(t, hs.Boards)
+	_, err = os.Stat(path + ".bad")
+	assert.NoError(t, err)
+	_, err = os.Stat(path)
+	assert.True(t, os.IsNotExist(err))
+
+	// The error tells if the file could not be put aside
+	os.WriteFile(path, []byte(`{"boards": {"a": [`), 0644)
+	os.Remove(path + ".bad")
+	os.Mkdir(path+".bad", 0755)
+	os.WriteFile(filepath.Join(path+".bad", "file"), nil, 0644)
+	_, err = LoadHighScores(path)
+	assert.ErrorContains(t, err, "could not move it to "+path+".bad")
+	_, err = os.Stat(path)
+	assert.NoError(t, err)
+}
@@ -0,0 +1,57 @@
+package snake
+
+// Max number of turns buffered for the coming ticks
+const INPUT_QUEUE_SIZE = 3
+
+// Buffers turns pressed between two ticks, so that quick turns
+// like UP then LEFT within one tick are both played, one per tick
+type InputQueue struct {
+	turns []int
+}
+
+// Returns the direction the snake will move after all the queued turns
+func (q *InputQueue) last(current int) int {
+	if len(q.turns) == 0 {
+		return current
+	}
+	return q.turns[len(q.turns)-1]
+}
+
+// True if the snake moving in direction from can turn to dir
+func valid_turn(from, dir int) bool {
+	return dir != from && dir != Opposite(from)
+}
+
+// Queue a turn, current is the direction the snake is moving now.
+// The turn is checked against the direction the snake will have when the
+// turn is played, turns that reverse it or go the same way are discarded,
+// as are turns that do not fit in the queue. Returns true if queued
+func (q *InputQueue) Push(dir, current int) bool {
+	if len(q.turns) >= INPUT_QUEUE_SIZE || !valid_turn(q.last(current), dir) {
+		return false
+	}
+	q.turns = append(q.turns, dir)
+	return true
+}
+
+// Returns the direction for the next tick, consuming one valid turn.
+// If there is no turn queued the snake keeps going in current direction
+func (q *InputQueue) Pop(current int) int {
+	for len(q.turns) > 0 {
+		dir := q.turns[0]
+		q.turns = q.turns[1:]
+		if valid_turn(current, dir) {
+			return dir
+		}
+	}
+	return current
+}
+
+// Number of turns queued
+func (q *InputQueue) Len() int {
+	return len(q.turns)
+}
+
+func (q *InputQueue) Clear() {
+	q.turns = q.turns[:0]
+}
@@ -0,0 +1,61 @@
+package snake
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestInputQueue(t *testing.T) {
+	q := InputQueue{}
+	// Nothing queued, keep going
+	assert.Equal(t, DOWN, q.Pop(DOWN))
+
+	// Same direction and reversal are discarded
+	assert.False(t, q.Push(DOWN, DOWN))
+	assert.False(t, q.Push(UP, DOWN))
+	assert.Equal(t, 0, q.Len())
+
+	// One turn per tick
+	assert.True(t, q.Push(LEFT, DOWN))
+	assert.True(t, q.Push(UP, DOWN))
+	assert.Equal(t, LEFT, q.Pop(DOWN))
+	assert.Equal(t, UP, q.Pop(LEFT))
+	assert.Equal(t, UP, q.Pop(UP))
+}
+
+func TestInputQueueUTurn(t *testing.T) {
+	q := InputQueue{}
+	// Moving down, quick RIGHT, UP makes a U-turn over two ticks
+	assert.True(t, q.Push(RIGHT, DOWN))
+	assert.True(t, q.Push(UP, DOWN))
+	// UP again, or DOWN after UP, is not a turn
+	assert.False(t, q.Push(UP, DOWN))
+	assert.False(t, q.Push(DOWN, DOWN))
+
+	ss := CreateSnake(10, 10)
+	ss.Apple = Point{1, 1}
+	ss.UpdateDirection(q.Pop(ss.Direction))
+	ss.Tick()
+	ss.UpdateDirection(q.Pop(ss.Direction))
+	ss.Tick()
+	assert.Equal(t, UP, ss.Direction)
+	assert.Equal(t, Point{6, 4}, ss.SnakeBody[len(ss.SnakeBody)-1].Cord)
+	assert.False(t, ss.GameOver)
+}
+
+func TestInputQueueFull(t *testing.T) {
+	q := InputQueue{}
+	assert.True(t, q.Push(LEFT, DOWN))
+	assert.True(t, q.Push(UP, DOWN))
+	assert.True(t, q.Push(RIGHT, DOWN))
+	assert.False(t, q.Push(DOWN, DOWN))
+	assert.Equal(t, INPUT_QUEUE_SIZE, q.Len())
+
+	// Turns no longer valid when they are played are skipped
+	assert.Equal(t, UP, q.Pop(RIGHT))
+	assert.Equal(t, RIGHT, q.Pop(UP))
+
+	q.Clear()
+	assert.Equal(t, 0, q.Len())
+}
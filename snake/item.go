@@ -0,0 +1,198 @@
+package snake
+
+import "log"
+
+// Kind of an item that shows up on the board besides the apple
+type ItemKind int
+
+const (
+	// Worth GOLDEN_APPLE_SCORE points
+	ITEM_GOLDEN_APPLE ItemKind = iota
+	// Cuts SHRINK_LENGTH cells off the tail
+	ITEM_SHRINK_PILL
+	// The snake moves faster for EFFECT_TICKS ticks
+	ITEM_SPEED_UP
+	// The snake moves slower for EFFECT_TICKS ticks
+	ITEM_SLOW_DOWN
+	// The snake goes through obstacles and itself for EFFECT_TICKS ticks
+	ITEM_INVINCIBILITY
+)
+
+const (
+	// Most items on the board at the same time, not counting the apple
+	MAX_ITEMS = 3
+
+	// Chance of a new item showing up in a tick
+	ITEM_SPAWN_CHANCE = 0.05
+
+	GOLDEN_APPLE_SCORE = 5
+
+	SHRINK_LENGTH = 3
+
+	// Ticks the effect of a speed or invincibility item lasts
+	EFFECT_TICKS = 60
+
+	// Tick interval is divided by this while a speed item is in effect
+	SPEED_UP_EFFECT  = 1.5
+	SLOW_DOWN_EFFECT = 0.6
+)
+
+type ItemSpec struct {
+	Name string
+
+	// Relative chance of this kind being picked when an item spawns
+	Weight int
+
+	// Ticks the item stays on the board if not eaten
+	Lifetime int
+}
+
+// Spawn weights and lifetimes, indexed by ItemKind
+var ITEM_SPECS = []ItemSpec{
+	ITEM_GOLDEN_APPLE:  {"golden apple", 4, 50},
+	ITEM_SHRINK_PILL:   {"shrink pill", 3, 60},
+	ITEM_SPEED_UP:      {"speed up", 2, 40},
+	ITEM_SLOW_DOWN:     {"slow down", 2, 40},
+	ITEM_INVINCIBILITY: {"invincibility", 1, 30},
+}
+
+func (k ItemKind) String() string {
+	return ITEM_SPECS[k].Name
+}
+
+// An item on the board
+type Item struct {
+	Kind ItemKind
+	Cord Point
+
+	// Ticks left before the item disappears
+	TicksLeft int
+}
+
+// Returns the item at p
+func (ss *SnakeState) ItemAt(p Point) (Item, bool) {
+	for _, item := range ss.Items {
+		if item.Cord == p {
+			return item, true
+		}
+	}
+	return Item{}, false
+}
+
+// True while the snake goes through obstacles and itself
+func (ss *SnakeState) Invincible() bool {
+	return ss.InvincibleTicks > 0
+}
+
+// Returns how much faster than normal the snake moves,
+// the tick interval should be divided by this
+func (ss *SnakeState) SpeedFactor() float64 {
+	switch {
+	case ss.SpeedUpTicks > 0:
+		return SPEED_UP_EFFECT
+	case ss.SlowDownTicks > 0:
+		return SLOW_DOWN_EFFECT
+	}
+	return 1
+}
+
+// Eat the item under the head, if any
+func (ss *SnakeState) maybe_consume_item(head Point) {
+	for i, item := range ss.Items {
+		if item.Cord != head {
+			continue
+		}
+		ss.Items = append(ss.Items[:i], ss.Items[i+1:]...)
+		ss.apply_item(item.Kind)
+		return
+	}
+}
+
+func (ss *SnakeState) apply_item(kind ItemKind) {
+	switch kind {
+	case ITEM_GOLDEN_APPLE:
+		ss.Score += GOLDEN_APPLE_SCORE
+		ss.Breakdown.Items += GOLDEN_APPLE_SCORE
+		ss.Grow += 1
+	case ITEM_SHRINK_PILL:
+		ss.shrink(SHRINK_LENGTH)
+	case ITEM_SPEED_UP:
+		// The latest speed item replaces the other one
+		ss.SpeedUpTicks = EFFECT_TICKS
+		ss.SlowDownTicks = 0
+	case ITEM_SLOW_DOWN:
+		ss.SlowDownTicks = EFFECT_TICKS
+		ss.SpeedUpTicks = 0
+	case ITEM_INVINCIBILITY:
+		ss.InvincibleTicks = EFFECT_TICKS
+	}
+}
+
+// Cut n cells off the tail, the head is always kept
+func (ss *SnakeState) shrink(n int) {
+	n = min(n, len(ss.SnakeBody)-1)
+	ss.SnakeBody = ss.SnakeBody[n:]
+	ss.Grow = 0
+	if len(ss.SnakeBody) > 1 {
+		tail := ss.SnakeBody[0].Cord
+		tail_type, err := get_tail_type(tail, ss.unwrap(ss.SnakeBody[1].Cord, tail))
+		if err != nil {
+			log.Fatal(err)
+		}
+		ss.SnakeBody[0].PartType = tail_type
+	}
+}
+
+// Age the items on the board and the effects of the ones eaten,
+// then maybe put a new item on the board
+func (ss *SnakeState) update_items() {
+	items := ss.Items[:0]
+	for _, item := range ss.Items {
+		item.TicksLeft -= 1
+		if item.TicksLeft > 0 {
+			items = append(items, item)
+		}
+	}
+	ss.Items = items
+
+	ss.SpeedUpTicks = max(ss.SpeedUpTicks-1, 0)
+	ss.SlowDownTicks = max(ss.SlowDownTicks-1, 0)
+	ss.InvincibleTicks = max(ss.InvincibleTicks-1, 0)
+
+	if len(ss.Items) < MAX_ITEMS && ss.rng.Float64() < ITEM_SPAWN_CHANCE {
+		ss.spawn_item(ss.random_item_kind())
+	}
+}
+
+// Pick a kind of item by the spawn weights
+func (ss *SnakeState) random_item_kind() ItemKind {
+	total := 0
+	for _, spec := range ITEM_SPECS {
+		total += spec.Weight
+	}
+	n := ss.rng.IntN(total)
+	for kind, spec := range ITEM_SPECS {
+		if n < spec.Weight {
+			return ItemKind(kind)
+		}
+		n -= spec.Weight
+	}
+	return ITEM_GOLDEN_APPLE
+}
+
+// Put an item of the kind on a random empty cell.
+// Returns false if there is no empty cell
+func (ss *SnakeState) spawn_item(kind ItemKind) bool {
+	cells := []Point{}
+	for _, p := range ss.free_cells() {
+		if _, taken := ss.ItemAt(p); !taken && p != ss.Apple {
+			cells = append(cells, p)
+		}
+	}
+	if len(cells) == 0 {
+		return false
+	}
+	p := cells[ss.rng.IntN(len(cells))]
+	ss.Items = append(ss.Items, Item{kind, p, ITEM_SPECS[kind].Lifetime})
+	return true
+}
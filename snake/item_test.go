@@ -0,0 +1,190 @@
+package snake
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// A snake of length 3 at the middle of a 10x10 board moving right
+func create_item_snake() *SnakeState {
+	ss := CreateSnake(10, 10, WithRules(Rules{Items: true}), WithSeed(1))
+	ss.SnakeBody = []SnakePart{
+		make_tail(3, 5, BODY_PART_TAIL_RIGHT),
+		make_body(4, 5),
+		make_head(5, 5, BODY_PART_HEAD_RIGHT)}
+	ss.Direction = RIGHT
+	ss.Apple = Point{1, 1}
+	return ss
+}
+
+func TestGoldenApple(t *testing.T) {
+	ss := create_item_snake()
+	ss.Items = []Item{{ITEM_GOLDEN_APPLE, Point{6, 5}, 10}}
+	ss.Tick()
+	assert.Equal(t, GOLDEN_APPLE_SCORE, ss.Score)
+	_, ok := ss.ItemAt(Point{6, 5})
+	assert.False(t, ok)
+
+	// The snake grows one cell on the next tick
+	ss.Tick()
+	assert.Len(t, ss.SnakeBody, 4)
+	assert.Equal(t, Point{4, 5}, ss.SnakeBody[0].Cord)
+	ss.Tick()
+	assert.Len(t, ss.SnakeBody, 4)
+}
+
+func TestGrowingTailBlocks(t *testing.T) {
+	ss := create_item_snake()
+	ss.SnakeBody = []SnakePart{
+		make_tail(4, 4, BODY_PART_TAIL_RIGHT),
+		make_body(5, 4),
+		make_body(5, 5),
+		make_head(4, 5, BODY_PART_HEAD_LEFT)}
+	ss.Direction = LEFT
+	ss.UpdateDirection(UP)
+	// The tail moves away
+	assert.False(t, ss.View().Blocked(Point{4, 4}))
+
+	// The tail stays while the snake grows from a golden apple
+	ss.Grow = 1
+	assert.True(t, ss.View().Blocked(Point{4, 4}))
+	ss.Tick()
+	assert.True(t, ss.GameOver)
+}
+
+func TestShrinkPill(t *testing.T) {
+	ss := create_item_snake()
+	ss.SnakeBody = []SnakePart{
+		make_tail(1, 5, BODY_PART_TAIL_RIGHT),
+		make_body(2, 5),
+		make_body(3, 5),
+		make_body(4, 5),
+		make_head(5, 5, BODY_PART_HEAD_RIGHT)}
+	ss.Items = []Item{{ITEM_SHRINK_PILL, Point{6, 5}, 10}}
+	ss.Tick()
+	assert.Len(t, ss.SnakeBody, 2)
+	assert.Equal(t, make_tail(5, 5, BODY_PART_TAIL_RIGHT), ss.SnakeBody[0])
+
+	// Never shorter than the head
+	ss.shrink(SHRINK_LENGTH)
+	assert.Len(t, ss.SnakeBody, 1)
+}
+
+func TestSpeedItems(t *testing.T) {
+	ss := create_item_snake()
+	assert.Equal(t, 1.0, ss.SpeedFactor())
+	ss.Items = []Item{
+		{ITEM_SPEED_UP, Point{6, 5}, 10},
+		{ITEM_SLOW_DOWN, Point{7, 5}, 10}}
+	ss.Tick()
+	assert.Equal(t, SPEED_UP_EFFECT, ss.SpeedFactor())
+	assert.Equal(t, EFFECT_TICKS-1, ss.SpeedUpTicks)
+
+	// The slow down replaces the speed up
+	ss.Tick()
+	assert.Equal(t, SLOW_DOWN_EFFECT, ss.SpeedFactor())
+	assert.Equal(t, 0, ss.SpeedUpTicks)
+
+	for i := 0; i < EFFECT_TICKS; i++ {
+		ss.Direction = []int{DOWN, LEFT, UP, RIGHT}[i%4]
+		ss.Tick()
+	}
+	assert.False(t, ss.GameOver)
+	assert.Equal(t, 1.0, ss.SpeedFactor())
+}
+
+func TestInvincibility(t *testing.T) {
+	ss := create_item_snake()
+	ss.Items = []Item{{ITEM_INVINCIBILITY, Point{6, 5}, 10}}
+	ss.Tick()
+	assert.True(t, ss.Invincible())
+
+	// Goes through obstacles
+	ss.obstacles = map[Point]bool{{7, 5}: true}
+	ss.Tick()
+	assert.False(t, ss.GameOver)
+	assert.Equal(t, Point{7, 5}, ss.SnakeBody[len(ss.SnakeBody)-1].Cord)
+	ss.obstacles = nil
+
+	// Goes through itself
+	ss.SnakeBody = []SnakePart{
+		make_tail(3, 4, BODY_PART_TAIL_DOWN),
+		make_body(3, 5),
+		make_body(3, 6),
+		make_body(2, 6),
+		make_head(2, 5, BODY_PART_HEAD_UP)}
+	ss.Direction = RIGHT
+	ss.Tick()
+	assert.False(t, ss.GameOver)
+
+	// Deadly again when the effect is over
+	ss.InvincibleTicks = 0
+	ss.obstacles = map[Point]bool{{7, 5}: true}
+	ss.SnakeBody = []SnakePart{make_head(6, 5, BODY_PART_HEAD_RIGHT)}
+	ss.Tick()
+	assert.True(t, ss.GameOver)
+}
+
+func TestInvincibleBoarder(t *testing.T) {
+	ss := create_item_snake()
+	ss.InvincibleTicks = EFFECT_TICKS
+	ss.SnakeBody = []SnakePart{make_head(8, 5, BODY_PART_HEAD_RIGHT)}
+	assert.True(t, ss.View().Blocked(ss.View().Neighbor(Point{8, 5}, RIGHT)))
+
+	// Does not go over the boarder to the other side
+	ss.Tick()
+	assert.True(t, ss.GameOver)
+	assert.Equal(t, Point{8, 5}, ss.SnakeBody[0].Cord)
+}
+
+func TestItemsExpire(t *testing.T) {
+	ss := create_item_snake()
+	ss.Items = []Item{
+		{ITEM_SHRINK_PILL, Point{2, 2}, 1},
+		{ITEM_SHRINK_PILL, Point{2, 3}, 3}}
+	ss.update_items()
+	assert.Len(t, ss.Items, 1)
+	assert.Equal(t, Point{2, 3}, ss.Items[0].Cord)
+	assert.Equal(t, 2, ss.Items[0].TicksLeft)
+}
+
+func TestSpawnItem(t *testing.T) {
+	ss := create_item_snake()
+	for i := 0; i < MAX_ITEMS; i++ {
+		assert.True(t, ss.spawn_item(ITEM_GOLDEN_APPLE))
+	}
+	for _, item := range ss.Items {
+		assert.NotEqual(t, ss.Apple, item.Cord)
+		assert.False(t, ss.snake_touched(item.Cord))
+		assert.Equal(t, ITEM_SPECS[ITEM_GOLDEN_APPLE].Lifetime, item.TicksLeft)
+	}
+
+	// No free cell left on a 3x3 board
+	ss = CreateSnake(3, 3, WithRules(Rules{Items: true}))
+	assert.False(t, ss.spawn_item(ITEM_GOLDEN_APPLE))
+
+	// Kinds are picked by weight
+	counts := make([]int, len(ITEM_SPECS))
+	ss = create_item_snake()
+	for i := 0; i < 10000; i++ {
+		counts[ss.random_item_kind()] += 1
+	}
+	assert.Greater(t, counts[ITEM_GOLDEN_APPLE], counts[ITEM_SPEED_UP])
+	assert.Greater(t, counts[ITEM_SPEED_UP], counts[ITEM_INVINCIBILITY])
+}
+
+func TestItemsRule(t *testing.T) {
+	play := func(rules Rules) *SnakeState {
+		ss := CreateSnake(20, 20, WithRules(rules), WithSeed(3))
+		ss.Direction = RIGHT
+		for i := 0; i < 100; i++ {
+			ss.UpdateDirection([]int{RIGHT, DOWN, LEFT, UP}[i/4%4])
+			ss.Tick()
+		}
+		return ss
+	}
+	// Items only show up with the rule
+	assert.Empty(t, play(Rules{}).Items)
+	assert.NotEmpty(t, play(Rules{Items: true}).Items)
+}
@@ -0,0 +1,232 @@
+package snake
+
+import (
+	"bufio"
+	"embed"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path"
+	"strconv"
+	"strings"
+)
+
+// Levels bundled with the game
+//
+//go:embed levels/*.txt
+var level_files embed.FS
+
+// A board layout with walls inside the boarder.
+//
+// Level files are text, some "key: value" headers followed by the board
+// as an ASCII grid, one line per row. The outer ring of the grid is the
+// boarder and must be all walls:
+//
+//	; lines starting with ; are comments
+//	name: Pillars
+//	goal: 15
+//
+//	##########
+//	#........#
+//	#.##..v..#
+//	#........#
+//	##########
+//
+// In the grid # is a wall, . or space a free cell, and one of ^ v < >
+// marks where the snake starts and the direction it starts moving.
+// The goal is the score to reach to complete the level in a campaign
+type Level struct {
+	Name   string `json:"name"`
+	Goal   int    `json:"goal,omitempty"`
+	Width  int    `json:"width"`
+	Height int    `json:"height"`
+
+	// Walls inside the boarder
+	Obstacles []Point `json:"obstacles"`
+
+	Start          Point `json:"start"`
+	StartDirection int   `json:"start_direction"`
+}
+
+// Goal of levels that do not set one
+const DEFAULT_LEVEL_GOAL = 10
+
+// Score to reach to complete the level
+func (level *Level) GoalScore() int {
+	if level.Goal > 0 {
+		return level.Goal
+	}
+	return DEFAULT_LEVEL_GOAL
+}
+
+// Returns an error if the rules do not apply to levels. The outer ring
+// of a level is walls, a board that wraps around would go through them
+func CheckLevelRules(rules Rules) error {
+	if rules.Wrap {
+		return errors.New("levels cannot be played on a board that wraps around")
+	}
+	return nil
+}
+
+var _START_DIRECTIONS = map[rune]int{
+	'^': UP,
+	'v': DOWN,
+	'<': LEFT,
+	'>': RIGHT,
+}
+
+// Parse a level file, see Level for the format
+func ParseLevel(r io.Reader) (*Level, error) {
+	level := &Level{}
+	rows := []string{}
+	start_found := false
+	scanner := bufio.NewScanner(r)
+	for line_no := 1; scanner.Scan(); line_no++ {
+		line := strings.TrimRight(scanner.Text(), "\r")
+		if strings.HasPrefix(line, ";") {
+			continue
+		}
+		if len(rows) == 0 {
+			// Headers before the grid
+			if strings.TrimSpace(line) == "" {
+				continue
+			}
+			if key, value, ok := strings.Cut(line, ":"); ok {
+				if err := level.set_header(strings.TrimSpace(key), strings.TrimSpace(value)); err != nil {
+					return nil, fmt.Errorf("line %d: %w", line_no, err)
+				}
+				continue
+			}
+		} else if strings.TrimSpace(line) == "" {
+			// The grid ends at the first empty line
+			break
+		}
+
+		y := len(rows)
+		for x, c := range line {
+			p := Point{x, y}
+			switch c {
+			case '#':
+				level.Obstacles = append(level.Obstacles, p)
+			case '.', ' ':
+			default:
+				dir, ok := _START_DIRECTIONS[c]
+				if !ok {
+					return nil, fmt.Errorf("line %d: unknown cell %q", line_no, c)
+				}
+				if start_found {
+					return nil, fmt.Errorf("line %d: more than one start", line_no)
+				}
+				start_found = true
+				level.Start = p
+				level.StartDirection = dir
+			}
+		}
+		rows = append(rows, line)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+
+	if len(rows) < 3 {
+		return nil, fmt.Errorf("board has %d rows, should have at least 3", len(rows))
+	}
+	level.Height = len(rows)
+	level.Width = len(rows[0])
+	for y, row := range rows {
+		if len(row) != level.Width {
+			return nil, fmt.Errorf("row %d is %d cells wide, should be %d", y, len(row), level.Width)
+		}
+	}
+	if level.Width < 3 {
+		return nil, fmt.Errorf("board is %d cells wide, should be at least 3", level.Width)
+	}
+	if !start_found {
+		return nil, fmt.Errorf("no start, mark it with one of ^ v < >")
+	}
+
+	// The boarder is implied, only keep walls inside it
+	obstacles := level.Obstacles[:0]
+	boarder := 0
+	for _, p := range level.Obstacles {
+		if p.X == 0 || p.Y == 0 || p.X == level.Width-1 || p.Y == level.Height-1 {
+			boarder += 1
+		} else {
+			obstacles = append(obstacles, p)
+		}
+	}
+	if boarder != 2*(level.Width+level.Height)-4 {
+		return nil, fmt.Errorf("boarder should be all walls")
+	}
+	level.Obstacles = obstacles
+	return level, nil
+}
+
+func (level *Level) set_header(key, value string) error {
+	switch key {
+	case "name":
+		level.Name = value
+	case "goal":
+		goal, err := strconv.Atoi(value)
+		if err != nil || goal <= 0 {
+			return fmt.Errorf("goal should be a positive number, got %q", value)
+		}
+		level.Goal = goal
+	default:
+		return fmt.Errorf("unknown header %q", key)
+	}
+	return nil
+}
+
+// Load a level file
+func LoadLevel(file string) (*Level, error) {
+	f, err := os.Open(file)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	level, err := ParseLevel(f)
+	if err != nil {
+		return nil, fmt.Errorf("invalid level %s: %w", file, err)
+	}
+	if level.Name == "" {
+		level.Name = strings.TrimSuffix(path.Base(file), path.Ext(file))
+	}
+	return level, nil
+}
+
+// Returns the levels bundled with the game, in order
+func Levels() []*Level {
+	names, err := fs.Glob(level_files, "levels/*.txt")
+	if err != nil {
+		panic(err)
+	}
+	levels := make([]*Level, 0, len(names))
+	for _, name := range names {
+		f, err := level_files.Open(name)
+		if err != nil {
+			panic(err)
+		}
+		level, err := ParseLevel(f)
+		f.Close()
+		if err != nil {
+			panic(fmt.Sprintf("invalid bundled level %s: %v", name, err))
+		}
+		levels = append(levels, level)
+	}
+	return levels
+}
+
+// Returns the bundled level with the name
+func FindLevel(name string) (*Level, error) {
+	names := []string{}
+	for _, level := range Levels() {
+		if strings.EqualFold(level.Name, name) {
+			return level, nil
+		}
+		names = append(names, level.Name)
+	}
+	return nil, fmt.Errorf("unknown level %q, should be one of %s", name, strings.Join(names, ", "))
+}
@@ -0,0 +1,100 @@
+package snake
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+const TEST_LEVEL = `; a small level
+name: Test
+goal: 3
+
+######
+#....#
+#.#<.#
+#....#
+######
+`
+
+func TestParseLevel(t *testing.T) {
+	level, err := ParseLevel(strings.NewReader(TEST_LEVEL))
+	assert.NoError(t, err)
+	assert.Equal(t, &Level{"Test", 3, 6, 5, []Point{{2, 2}}, Point{3, 2}, LEFT}, level)
+}
+
+func TestParseLevelErrors(t *testing.T) {
+	for _, data := range []string{
+		// no start
+		"####\n#..#\n####\n",
+		// two starts
+		"#####\n#<.>#\n#####\n",
+		// rows of different length
+		"####\n#<.#\n###\n",
+		// hole in the boarder
+		"####\n#<..\n####\n",
+		// unknown cell
+		"####\n#<x#\n####\n",
+		// unknown header
+		"size: 3\n####\n#<.#\n####\n",
+		// invalid goal
+		"goal: many\n####\n#<.#\n####\n",
+		// too small
+		"###\n#<#\n",
+	} {
+		_, err := ParseLevel(strings.NewReader(data))
+		assert.Error(t, err, data)
+	}
+}
+
+func TestLoadLevel(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "small.txt")
+	// Name defaults to the file name
+	assert.NoError(t, os.WriteFile(file, []byte("####\r\n#>.#\r\n####\r\n"), 0644))
+	level, err := LoadLevel(file)
+	assert.NoError(t, err)
+	assert.Equal(t, "small", level.Name)
+	assert.Equal(t, 4, level.Width)
+
+	_, err = LoadLevel(filepath.Join(t.TempDir(), "missing.txt"))
+	assert.Error(t, err)
+}
+
+func TestBundledLevels(t *testing.T) {
+	levels := Levels()
+	assert.GreaterOrEqual(t, len(levels), 5)
+	for _, level := range levels {
+		// Every level is playable for a while
+		ss := CreateSnake(level.Height, level.Width, WithLevel(level), WithSeed(1))
+		ss.Tick()
+		assert.False(t, ss.GameOver, level.Name)
+		assert.True(t, ss.HasApple(), level.Name)
+	}
+
+	level, err := FindLevel("pillars")
+	assert.NoError(t, err)
+	assert.Equal(t, "Pillars", level.Name)
+	_, err = FindLevel("nowhere")
+	assert.Error(t, err)
+}
+
+func TestLevelObstacles(t *testing.T) {
+	level, _ := ParseLevel(strings.NewReader(TEST_LEVEL))
+	ss := CreateSnake(0, 0, WithLevel(level))
+	assert.Equal(t, 6, ss.Width)
+	assert.Equal(t, 5, ss.Height)
+	assert.Equal(t, []SnakePart{make_head(3, 2, BODY_PART_HEAD_LEFT)}, ss.SnakeBody)
+	assert.Equal(t, LEFT, ss.Direction)
+
+	// Apples are never placed on obstacles
+	assert.Len(t, ss.free_cells(), 4*3-2)
+	assert.NotContains(t, ss.free_cells(), Point{2, 2})
+
+	// Running into the obstacle ends the game
+	ss.Apple = Point{1, 1}
+	ss.Tick()
+	assert.True(t, ss.GameOver)
+}
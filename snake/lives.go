@@ -0,0 +1,75 @@
+package snake
+
+const (
+	// Ticks the snake waits after losing a life
+	RESPAWN_TICKS = 10
+
+	// Free cells a respawned snake has in front of it
+	RESPAWN_CLEARANCE = 3
+)
+
+// Take a life and put the snake back on the board as a single cell,
+// somewhere it does not run into anything in the next few ticks
+func (ss *SnakeState) lose_life() {
+	ss.Lives -= 1
+	ss.SnakeBody = nil
+	// Effects of the items eaten end with the life
+	ss.Grow = 0
+	ss.SpeedUpTicks = 0
+	ss.SlowDownTicks = 0
+	ss.InvincibleTicks = 0
+	ss.Combo = 0
+
+	cell, dir, ok := ss.find_respawn(RESPAWN_CLEARANCE)
+	if !ok {
+		// Crowded board, any cell that does not end the game right away
+		cell, dir, ok = ss.find_respawn(1)
+	}
+	if !ok {
+		ss.Lives = 0
+		ss.GameOver = true
+		return
+	}
+	ss.SnakeBody = []SnakePart{{cell, _HEAD_TYPE_FROM_DIR[dir]}}
+	ss.Direction = dir
+	ss.Respawning = RESPAWN_TICKS
+}
+
+// Returns a random free cell and a direction with at least
+// clearance free cells in front of it
+func (ss *SnakeState) find_respawn(clearance int) (Point, int, bool) {
+	type spawn struct {
+		cell Point
+		dir  int
+	}
+	candidates := []spawn{}
+	for _, cell := range ss.free_cells() {
+		if cell == ss.Apple {
+			continue
+		}
+		for _, dir := range DIRECTIONS {
+			if ss.clear_ahead(cell, dir, clearance) {
+				candidates = append(candidates, spawn{cell, dir})
+			}
+		}
+	}
+	if len(candidates) == 0 {
+		return Point{}, 0, false
+	}
+	c := candidates[ss.rng.IntN(len(candidates))]
+	return c.cell, c.dir, true
+}
+
+// True if the n cells in front of p in direction dir are free
+func (ss *SnakeState) clear_ahead(p Point, dir, n int) bool {
+	for i := 0; i < n; i++ {
+		p = p.Next(dir)
+		if ss.Rules.Wrap {
+			p = ss.wrap(p)
+		}
+		if ss.snake_touched(p) {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,64 @@
+package snake
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLives(t *testing.T) {
+	// One life without the rule
+	assert.Equal(t, 1, CreateSnake(10, 10).Lives)
+	assert.Equal(t, 2, CreateSnake(10, 10, WithLives(2), WithRules(Rules{Lives: 3})).Lives)
+
+	ss := CreateSnake(10, 10, WithRules(Rules{Lives: 2}), WithSeed(1))
+	assert.Equal(t, 2, ss.Lives)
+	ss.SnakeBody = []SnakePart{
+		make_tail(3, 5, BODY_PART_TAIL_LEFT),
+		make_body(2, 5),
+		make_head(1, 5, BODY_PART_HEAD_LEFT)}
+	ss.Direction = LEFT
+	ss.Score = 4
+
+	// Running into the boarder costs a life, the score is kept
+	e := NewEngine(ss)
+	assert.Equal(t, EVENT_LOST_LIFE, e.Step(LEFT))
+	assert.False(t, ss.GameOver)
+	assert.Equal(t, 1, ss.Lives)
+	assert.Equal(t, 4, ss.Score)
+	assert.Equal(t, RESPAWN_TICKS, ss.Respawning)
+
+	// Back as a single cell with room in front of it
+	assert.Len(t, ss.SnakeBody, 1)
+	head := ss.SnakeBody[0]
+	assert.Equal(t, _HEAD_TYPE_FROM_DIR[ss.Direction], head.PartType)
+	assert.True(t, ss.clear_ahead(head.Cord, ss.Direction, RESPAWN_CLEARANCE))
+
+	// The snake waits before moving again
+	for i := 0; i < RESPAWN_TICKS; i++ {
+		ss.Tick()
+	}
+	assert.Equal(t, head, ss.SnakeBody[0])
+	assert.Equal(t, 0, ss.Respawning)
+	ss.Tick()
+	assert.NotEqual(t, head.Cord, ss.SnakeBody[0].Cord)
+
+	// Last life ends the game
+	for i := 0; i < 20 && !ss.GameOver; i++ {
+		ss.Tick()
+	}
+	assert.True(t, ss.GameOver)
+	assert.Equal(t, 0, ss.Lives)
+}
+
+func TestRespawnCrowded(t *testing.T) {
+	// 4x4 board with 4 cells to play, no cell has 3 free cells in front
+	ss := CreateSnake(4, 4, WithRules(Rules{Lives: 2}))
+	ss.Apple = Point{2, 2}
+	ss.lose_life()
+	assert.False(t, ss.GameOver)
+	assert.Len(t, ss.SnakeBody, 1)
+	head := ss.SnakeBody[0].Cord
+	assert.NotEqual(t, ss.Apple, head)
+	assert.True(t, ss.clear_ahead(head, ss.Direction, 1))
+}
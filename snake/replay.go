@@ -0,0 +1,166 @@
+package snake
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+)
+
+// Version of the replay file format, bumped on incompatible changes.
+// Version 2 added the level and the lives at the start
+const REPLAY_VERSION = 2
+
+// Letter recorded for each direction, indexed by UP, LEFT, DOWN, RIGHT
+const _DIRECTION_LETTERS = "ULDR"
+
+// A recorded game. Everything needed to create the same SnakeState
+// plus the direction given before every tick, so that playing back
+// the inputs reproduces the exact game
+type Replay struct {
+	Version int    `json:"version"`
+	Seed    uint64 `json:"seed"`
+	Height  int    `json:"height"`
+	Width   int    `json:"width"`
+	Rules   Rules  `json:"rules"`
+	Level   *Level `json:"level,omitempty"`
+
+	// Lives at the start, differs from the rules in a campaign
+	Lives int `json:"lives"`
+
+	// Direction passed to UpdateDirection before each tick,
+	// one letter of _DIRECTION_LETTERS per tick
+	Inputs string `json:"inputs"`
+
+	// Outcome of the recorded game, checked by Verify
+	Score    int  `json:"score"`
+	GameOver bool `json:"game_over"`
+	GameWon  bool `json:"game_won"`
+}
+
+// Start recording a game, ss must not have been ticked yet
+func NewReplay(ss *SnakeState) *Replay {
+	return &Replay{
+		REPLAY_VERSION,
+		ss.Seed,
+		ss.Height,
+		ss.Width,
+		ss.Rules,
+		ss.Level,
+		ss.Lives,
+		"",
+		ss.Score,
+		ss.GameOver,
+		ss.GameWon,
+	}
+}
+
+// Record the direction used for one tick of ss, and the outcome after the tick
+func (r *Replay) Record(dir int, ss *SnakeState) {
+	r.Inputs += string(_DIRECTION_LETTERS[dir])
+	r.Score = ss.Score
+	r.GameOver = ss.GameOver
+	r.GameWon = ss.GameWon
+}
+
+// Number of ticks recorded
+func (r *Replay) Ticks() int {
+	return len(r.Inputs)
+}
+
+// Create the SnakeState the recorded game started with
+func (r *Replay) CreateSnake() *SnakeState {
+	return CreateSnake(
+		r.Height,
+		r.Width,
+		WithSeed(r.Seed),
+		WithRules(r.Rules),
+		WithLevel(r.Level),
+		WithLives(r.Lives))
+}
+
+// Returns the direction recorded for a tick
+func (r *Replay) Input(tick int) (int, error) {
+	dir := strings.IndexByte(_DIRECTION_LETTERS, r.Inputs[tick])
+	if dir < 0 {
+		return 0, fmt.Errorf("invalid input %q at tick %d", r.Inputs[tick], tick)
+	}
+	return dir, nil
+}
+
+// Play back all the inputs without drawing anything, returns an error
+// if the game does not end the same way it was recorded
+func (r *Replay) Verify() error {
+	playback := NewPlayback(r)
+	for !playback.Done() {
+		if err := playback.Step(); err != nil {
+			return err
+		}
+	}
+	ss := playback.State
+	if ss.Score != r.Score || ss.GameOver != r.GameOver || ss.GameWon != r.GameWon {
+		return fmt.Errorf(
+			"replay mismatch: recorded score %d (game over %v, won %v), played back score %d (game over %v, won %v)",
+			r.Score, r.GameOver, r.GameWon, ss.Score, ss.GameOver, ss.GameWon)
+	}
+	return nil
+}
+
+func SaveReplay(path string, r *Replay) error {
+	data, err := json.MarshalIndent(r, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, data, 0644)
+}
+
+func LoadReplay(path string) (*Replay, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	r := &Replay{}
+	if err := json.Unmarshal(data, r); err != nil {
+		return nil, fmt.Errorf("invalid replay %s: %w", path, err)
+	}
+	if r.Version != REPLAY_VERSION {
+		return nil, fmt.Errorf("replay %s has version %d, want %d", path, r.Version, REPLAY_VERSION)
+	}
+	if r.Height < 3 || r.Width < 3 {
+		return nil, fmt.Errorf("replay %s has invalid board size %dx%d", path, r.Width, r.Height)
+	}
+	return r, nil
+}
+
+// Drives a SnakeState with the inputs of a replay
+type Playback struct {
+	Replay *Replay
+	State  *SnakeState
+
+	// Next tick to play
+	tick int
+}
+
+func NewPlayback(r *Replay) *Playback {
+	return &Playback{r, r.CreateSnake(), 0}
+}
+
+// True if all the recorded ticks are played
+func (p *Playback) Done() bool {
+	return p.tick >= p.Replay.Ticks()
+}
+
+// Play one recorded tick
+func (p *Playback) Step() error {
+	if p.Done() {
+		return nil
+	}
+	dir, err := p.Replay.Input(p.tick)
+	if err != nil {
+		return err
+	}
+	p.tick += 1
+	p.State.UpdateDirection(dir)
+	p.State.Tick()
+	return nil
+}
@@ -0,0 +1,86 @@
+package snake
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// Record a game played with a fixed sequence of directions
+func record_game(seed uint64, dirs []int) (*Replay, *SnakeState) {
+	ss := CreateSnake(10, 10, WithSeed(seed))
+	r := NewReplay(ss)
+	for tick := 0; !ss.GameOver && tick < 200; tick++ {
+		dir := dirs[tick%len(dirs)]
+		ss.UpdateDirection(dir)
+		ss.Tick()
+		r.Record(dir, ss)
+	}
+	return r, ss
+}
+
+func TestReplayRoundTrip(t *testing.T) {
+	dirs := []int{LEFT, LEFT, UP, UP, RIGHT, RIGHT, RIGHT, DOWN, DOWN, DOWN, LEFT}
+	r, ss := record_game(7, dirs)
+	assert.Equal(t, ss.Score, r.Score)
+	assert.Equal(t, ss.GameOver, r.GameOver)
+
+	path := filepath.Join(t.TempDir(), "game.json")
+	assert.NoError(t, SaveReplay(path, r))
+	loaded, err := LoadReplay(path)
+	assert.NoError(t, err)
+	assert.Equal(t, r, loaded)
+	assert.NoError(t, loaded.Verify())
+
+	// Playback reproduces the exact same state
+	playback := NewPlayback(loaded)
+	for !playback.Done() {
+		assert.NoError(t, playback.Step())
+	}
+	assert.Equal(t, ss.SnakeBody, playback.State.SnakeBody)
+	assert.Equal(t, ss.Apple, playback.State.Apple)
+}
+
+func TestReplayVerifyMismatch(t *testing.T) {
+	r, _ := record_game(7, []int{LEFT, UP, RIGHT, DOWN})
+	r.Score += 1
+	assert.Error(t, r.Verify())
+
+	r, _ = record_game(7, []int{LEFT, UP, RIGHT, DOWN})
+	r.Inputs = r.Inputs[:len(r.Inputs)-1] + "X"
+	assert.Error(t, r.Verify())
+}
+
+func TestLoadReplayVersion(t *testing.T) {
+	r, _ := record_game(7, []int{LEFT})
+	r.Version = REPLAY_VERSION + 1
+	path := filepath.Join(t.TempDir(), "game.json")
+	assert.NoError(t, SaveReplay(path, r))
+	_, err := LoadReplay(path)
+	assert.Error(t, err)
+
+	// Replays from before levels and lives do not know the rules they were played with
+	r.Version = 1
+	assert.NoError(t, SaveReplay(path, r))
+	_, err = LoadReplay(path)
+	assert.ErrorContains(t, err, "has version 1")
+}
+
+func TestReplayLevel(t *testing.T) {
+	level, _ := FindLevel("cross")
+	ss := CreateSnake(level.Height, level.Width, WithLevel(level), WithSeed(3))
+	r := NewReplay(ss)
+	for _, dir := range []int{RIGHT, RIGHT, DOWN, DOWN, DOWN, DOWN, DOWN, DOWN, DOWN, DOWN} {
+		ss.UpdateDirection(dir)
+		ss.Tick()
+		r.Record(dir, ss)
+	}
+
+	path := filepath.Join(t.TempDir(), "game.json")
+	assert.NoError(t, SaveReplay(path, r))
+	loaded, err := LoadReplay(path)
+	assert.NoError(t, err)
+	assert.Equal(t, level, loaded.Level)
+	assert.NoError(t, loaded.Verify())
+}
@@ -0,0 +1,102 @@
+package snake
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/rand/v2"
+	"os"
+	"path/filepath"
+)
+
+// Version of the save file format, bumped on incompatible changes
+const SAVE_VERSION = 1
+
+// A game in progress, written to a file to be resumed later.
+// The resumed game plays out exactly like the saved one would have
+type SavedGame struct {
+	Version int         `json:"version"`
+	State   *SnakeState `json:"state"`
+
+	// State of the game's random source
+	RNG []byte `json:"rng"`
+
+	// Pace of the game, so that the next tick comes at the same frame
+	Speed  Speed  `json:"speed"`
+	Ticker Ticker `json:"ticker"`
+
+	// Frames played
+	Frames uint64 `json:"frames"`
+
+	// Recording of the game so far, nil if not recorded
+	Replay *Replay `json:"replay,omitempty"`
+
+	// Campaign being played, nil if none
+	Campaign *Campaign `json:"campaign,omitempty"`
+}
+
+// Returns a save of the game, the other fields are left for the caller
+func NewSavedGame(ss *SnakeState) (*SavedGame, error) {
+	rng, err := ss.rng_src.MarshalBinary()
+	if err != nil {
+		return nil, err
+	}
+	return &SavedGame{Version: SAVE_VERSION, State: ss, RNG: rng}, nil
+}
+
+// Returns the saved game state, ready to be ticked
+func (sg *SavedGame) Restore() (*SnakeState, error) {
+	ss := &SnakeState{}
+	*ss = *sg.State
+	ss.obstacles = level_obstacles(ss.Level)
+	ss.rng_src = &rand.PCG{}
+	if err := ss.rng_src.UnmarshalBinary(sg.RNG); err != nil {
+		return nil, fmt.Errorf("invalid random state: %w", err)
+	}
+	ss.rng = rand.New(ss.rng_src)
+	return ss, nil
+}
+
+// Returns the default file for a game saved on quit
+func DefaultSavePath() (string, error) {
+	dir, err := ConfigDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "save.json"), nil
+}
+
+func SaveGame(path string, sg *SavedGame) error {
+	data, err := json.Marshal(sg)
+	if err != nil {
+		return err
+	}
+	return WriteFileAtomic(path, data, 0644)
+}
+
+func LoadGame(path string) (*SavedGame, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	sg := &SavedGame{}
+	if err := json.Unmarshal(data, sg); err != nil {
+		return nil, fmt.Errorf("invalid save %s: %w", path, err)
+	}
+	if sg.Version != SAVE_VERSION {
+		return nil, fmt.Errorf("save %s has version %d, expected %d", path, sg.Version, SAVE_VERSION)
+	}
+	if sg.State == nil || sg.State.Width <= 0 || sg.State.Height <= 0 || len(sg.State.SnakeBody) == 0 {
+		return nil, fmt.Errorf("save %s has no game", path)
+	}
+	return sg, nil
+}
+
+// Remove the save, e.g. once the game is over. No file is not an error
+func RemoveSave(path string) error {
+	err := os.Remove(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+	return err
+}
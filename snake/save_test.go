@@ -0,0 +1,111 @@
+package snake
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// Takes the shortest way to the apple around walls and the snake,
+// keeps the snake alive long enough to eat apples and items
+type save_test_bot struct{}
+
+func (b save_test_bot) NextDirection(v SnakeView) int {
+	// first move of the way to each cell reached
+	first := map[Point]int{}
+	queue := []Point{}
+	for _, dir := range DIRECTIONS {
+		next := v.Neighbor(v.Head(), dir)
+		if dir != Opposite(v.Direction()) && !v.Blocked(next) {
+			first[next] = dir
+			queue = append(queue, next)
+		}
+	}
+	for len(queue) > 0 {
+		cur := queue[0]
+		queue = queue[1:]
+		if cur == v.Apple() {
+			return first[cur]
+		}
+		for _, dir := range DIRECTIONS {
+			next := v.Neighbor(cur, dir)
+			if _, seen := first[next]; !seen && !v.Blocked(next) {
+				first[next] = first[cur]
+				queue = append(queue, next)
+			}
+		}
+	}
+	return v.Direction()
+}
+
+func TestSaveRoundTrip(t *testing.T) {
+	for _, opts := range [][]SnakeOption{
+		{WithSeed(1)},
+		{WithSeed(2), WithRules(Rules{Wrap: true, Lives: 3, Items: true, TimedApples: true})},
+		{WithSeed(3), WithRules(Rules{Lives: 2, Items: true}), WithLevel(Levels()[1])},
+	} {
+		path := filepath.Join(t.TempDir(), "save.json")
+		ss := CreateSnake(20, 20, opts...)
+		e := NewEngine(ss)
+		for tick := 0; tick < 50; tick++ {
+			e.StepController(save_test_bot{})
+		}
+		assert.False(t, ss.GameOver)
+		apples := ss.Breakdown.Apples
+		assert.Greater(t, apples, 0)
+
+		sg, err := NewSavedGame(ss)
+		assert.NoError(t, err)
+		sg.Frames = 123
+		sg.Ticker = Ticker{7}
+		assert.NoError(t, SaveGame(path, sg))
+		loaded, err := LoadGame(path)
+		assert.NoError(t, err)
+		assert.Equal(t, uint64(123), loaded.Frames)
+		assert.Equal(t, Ticker{7}, loaded.Ticker)
+		resumed, err := loaded.Restore()
+		assert.NoError(t, err)
+		assert.Equal(t, ss, resumed)
+
+		// Both games go on the same way, down to the random apples and items
+		resumed_engine := NewEngine(resumed)
+		items := 0
+		for tick := 50; tick < 300; tick++ {
+			assert.Equal(t, e.StepController(save_test_bot{}), resumed_engine.StepController(save_test_bot{}))
+			items = max(items, len(ss.Items))
+		}
+		assert.Equal(t, ss, resumed)
+		assert.False(t, ss.GameOver)
+		assert.Greater(t, ss.Breakdown.Apples, apples)
+		if ss.Rules.Items {
+			assert.Greater(t, items, 0)
+		}
+	}
+}
+
+func TestLoadGameErrors(t *testing.T) {
+	dir := t.TempDir()
+	_, err := LoadGame(filepath.Join(dir, "none.json"))
+	assert.Error(t, err)
+
+	path := filepath.Join(dir, "save.json")
+	sg, _ := NewSavedGame(CreateSnake(10, 10))
+	sg.Version = SAVE_VERSION + 1
+	assert.NoError(t, SaveGame(path, sg))
+	_, err = LoadGame(path)
+	assert.ErrorContains(t, err, "version")
+
+	sg.Version = SAVE_VERSION
+	sg.RNG = []byte("junk")
+	assert.NoError(t, SaveGame(path, sg))
+	loaded, err := LoadGame(path)
+	assert.NoError(t, err)
+	_, err = loaded.Restore()
+	assert.Error(t, err)
+
+	assert.NoError(t, RemoveSave(path))
+	assert.NoError(t, RemoveSave(path))
+	_, err = LoadGame(path)
+	assert.Error(t, err)
+}
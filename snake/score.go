@@ -0,0 +1,76 @@
+package snake
+
+const (
+	// Most bonus points for eating an apple right after it shows up,
+	// see Rules.TimedApples
+	MAX_SPEED_BONUS = 5
+
+	// Longest combo counted, the points of an apple are multiplied
+	// by up to MAX_COMBO+1
+	MAX_COMBO = 4
+)
+
+// Where the points of a game came from, the parts add up to SnakeState.Score
+type ScoreBreakdown struct {
+	// One point for every apple eaten
+	Apples int
+
+	// Points for eating apples before their countdown ran low
+	SpeedBonus int
+
+	// Extra points from the combo multiplier
+	ComboBonus int
+
+	// Points from items like golden apples
+	Items int
+}
+
+func (b ScoreBreakdown) Total() int {
+	return b.Apples + b.SpeedBonus + b.ComboBonus + b.Items
+}
+
+// Ticks an apple stays on the board under Rules.TimedApples,
+// about the time to go across the board and back
+func (ss *SnakeState) AppleCountdown() int {
+	return ss.Width + ss.Height
+}
+
+// Current combo multiplier of the apple points
+func (ss *SnakeState) ComboMultiplier() int {
+	return 1 + min(ss.Combo, MAX_COMBO)
+}
+
+// Add the points of the apple just eaten
+func (ss *SnakeState) score_apple() {
+	ss.Breakdown.Apples += 1
+	ss.Score += 1
+	if !ss.Rules.TimedApples {
+		return
+	}
+	countdown := ss.AppleCountdown()
+	bonus := MAX_SPEED_BONUS * ss.AppleTicks / countdown
+	// The multiplier is for the apples eaten before, this apple only
+	// keeps the combo going if eaten in the first half of its countdown
+	combo_bonus := (1 + bonus) * (ss.ComboMultiplier() - 1)
+	if ss.AppleTicks*2 >= countdown {
+		ss.Combo += 1
+	} else {
+		ss.Combo = 0
+	}
+	ss.Breakdown.SpeedBonus += bonus
+	ss.Breakdown.ComboBonus += combo_bonus
+	ss.Score += bonus + combo_bonus
+}
+
+// Count down the apple on the board, it goes away when the time is up
+// and the combo is lost
+func (ss *SnakeState) update_apple_countdown() {
+	if !ss.HasApple() {
+		return
+	}
+	ss.AppleTicks -= 1
+	if ss.AppleTicks <= 0 {
+		ss.Apple = Point{-1, -1}
+		ss.Combo = 0
+	}
+}
@@ -0,0 +1,82 @@
+package snake
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// A snake at the left of a 10x10 board moving right to an apple in front of it
+func create_timed_snake() *SnakeState {
+	ss := CreateSnake(10, 10, WithRules(Rules{TimedApples: true}), WithSeed(1))
+	ss.SnakeBody = []SnakePart{make_head(1, 5, BODY_PART_HEAD_RIGHT)}
+	ss.Direction = RIGHT
+	ss.Apple = Point{2, 5}
+	ss.AppleTicks = ss.AppleCountdown()
+	return ss
+}
+
+func TestSpeedBonus(t *testing.T) {
+	// Eaten right away, full bonus
+	ss := create_timed_snake()
+	ss.Tick()
+	assert.Equal(t, ScoreBreakdown{1, MAX_SPEED_BONUS, 0, 0}, ss.Breakdown)
+	assert.Equal(t, 1+MAX_SPEED_BONUS, ss.Score)
+	assert.Equal(t, 1, ss.Combo)
+	assert.Equal(t, ss.AppleCountdown(), ss.AppleTicks)
+
+	// Eaten late, no bonus and the combo is lost
+	ss = create_timed_snake()
+	ss.Combo = 2
+	ss.AppleTicks = 1
+	ss.Tick()
+	assert.Equal(t, ScoreBreakdown{1, 0, 2, 0}, ss.Breakdown)
+	assert.Equal(t, 3, ss.Score)
+	assert.Equal(t, 0, ss.Combo)
+
+	// No bonus without the rule
+	ss = create_timed_snake()
+	ss.Rules = Rules{}
+	ss.Tick()
+	assert.Equal(t, ScoreBreakdown{1, 0, 0, 0}, ss.Breakdown)
+	assert.Equal(t, 1, ss.Score)
+}
+
+func TestCombo(t *testing.T) {
+	ss := create_timed_snake()
+	for i := 0; i < MAX_COMBO+2; i++ {
+		assert.Equal(t, 1+min(i, MAX_COMBO), ss.ComboMultiplier())
+		ss.Apple = ss.SnakeBody[len(ss.SnakeBody)-1].Cord.Next(DOWN)
+		ss.AppleTicks = ss.AppleCountdown()
+		ss.Direction = DOWN
+		if i%2 == 1 {
+			ss.Apple = ss.SnakeBody[len(ss.SnakeBody)-1].Cord.Next(UP)
+			ss.Direction = UP
+		}
+		ss.SnakeBody = ss.SnakeBody[len(ss.SnakeBody)-1:]
+		ss.Tick()
+		assert.False(t, ss.GameOver)
+	}
+	assert.Equal(t, MAX_COMBO+2, ss.Combo)
+	assert.Equal(t, ss.Breakdown.Total(), ss.Score)
+	// 0+1+2+3+4+4 times the apple and its bonus
+	assert.Equal(t, 14*(1+MAX_SPEED_BONUS), ss.Breakdown.ComboBonus)
+}
+
+func TestAppleExpires(t *testing.T) {
+	ss := create_timed_snake()
+	ss.Apple = Point{8, 8}
+	ss.AppleTicks = 2
+	ss.Combo = 3
+	ss.Direction = DOWN
+	ss.Tick()
+	assert.Equal(t, Point{8, 8}, ss.Apple)
+	assert.Equal(t, 1, ss.AppleTicks)
+
+	// Time is up, a new apple shows up
+	ss.Tick()
+	assert.True(t, ss.HasApple())
+	assert.Equal(t, ss.AppleCountdown(), ss.AppleTicks)
+	assert.Equal(t, 0, ss.Combo)
+	assert.Equal(t, 0, ss.Score)
+}
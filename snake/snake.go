@@ -3,7 +3,7 @@ package snake
 import (
 	"fmt"
 	"log"
-	"math/rand"
+	"math/rand/v2"
 )
 
 type Point struct {
@@ -11,6 +11,21 @@ type Point struct {
 	Y int
 }
 
+// Returns the adjacent point in the direction
+func (p Point) Next(dir int) Point {
+	switch dir {
+	case UP:
+		p.Y -= 1
+	case DOWN:
+		p.Y += 1
+	case LEFT:
+		p.X -= 1
+	case RIGHT:
+		p.X += 1
+	}
+	return p
+}
+
 // Part of a snake body, with a coordinate for the location
 // and indicate for head/tail/body
 type SnakePart struct {
@@ -26,6 +41,14 @@ const (
 	RIGHT = iota
 )
 
+// All the moving directions
+var DIRECTIONS = [4]int{UP, LEFT, DOWN, RIGHT}
+
+// Returns the reversed direction, e.g. DOWN for UP
+func Opposite(dir int) int {
+	return (dir + 2) % 4
+}
+
 const (
 	BODY_PART_HEAD_UP    = iota
 	BODY_PART_HEAD_LEFT  = iota
@@ -43,6 +66,25 @@ const (
 	BODY_PART_BODY_L3    = iota // L turned clockwise 270 degree
 )
 
+// Rules of a game, the same seed and inputs only play out the same
+// game under the same rules
+type Rules struct {
+	// The board has no boarder, the snake going over an edge
+	// comes back from the opposite edge
+	Wrap bool `json:"wrap,omitempty"`
+
+	// Number of lives at the start of the game, touching a wall or
+	// the snake itself costs one. 0 is the same as 1
+	Lives int `json:"lives,omitempty"`
+
+	// Golden apples, shrink pills and power-ups show up on the board
+	Items bool `json:"items,omitempty"`
+
+	// Apples go away if not eaten in time, eating them early
+	// and in a row scores more
+	TimedApples bool `json:"timed_apples,omitempty"`
+}
+
 // Represents the state of a snake game
 type SnakeState struct {
 	// snake body is a list of points
@@ -55,45 +97,225 @@ type SnakeState struct {
 	// Location of the apple
 	Apple Point
 
+	// Ticks left before the apple goes away, see Rules.TimedApples
+	AppleTicks int
+
+	// Items on the board besides the apple, see Rules.Items
+	Items []Item
+
 	// size of the game board
 	Height int
 	Width  int
 
+	// Rules of this game, set by WithRules
+	Rules Rules
+
+	// Layout of the board, nil for an empty board
+	Level *Level
+
+	// Cells of the level's obstacles
+	obstacles map[Point]bool
+
 	// True if game over
 	GameOver bool
 
-	// Game score, number of apples ate
+	// True if the game ended because the snake filled the board,
+	// GameOver is also set
+	GameWon bool
+
+	// Game score, the points of apples and items eaten
 	Score int
+
+	// Where the points of the score came from
+	Breakdown ScoreBreakdown
+
+	// Number of apples eaten in a row in the first half of their countdown
+	Combo int
+
+	// Lives left, including the one being played
+	Lives int
+
+	// Ticks left before a snake that lost a life starts moving again
+	Respawning int
+
+	// Cells the snake grows in the next ticks, besides eating the apple
+	Grow int
+
+	// Ticks left of the effects of the items eaten
+	SpeedUpTicks    int
+	SlowDownTicks   int
+	InvincibleTicks int
+
+	// Seed of the random source, the same seed and the same
+	// sequence of directions always plays out the same game
+	Seed uint64
+
+	// Random source owned by this game, seeded with Seed
+	rng_src *rand.PCG
+	rng     *rand.Rand
+}
+
+// Option for CreateSnake
+type SnakeOption func(*SnakeState)
+
+// Seed the random source of the game.
+// Without this option a random seed is picked, see SnakeState.Seed
+func WithSeed(seed uint64) SnakeOption {
+	return func(ss *SnakeState) {
+		ss.Seed = seed
+	}
+}
+
+// Play the game with the given rules
+func WithRules(rules Rules) SnakeOption {
+	return func(ss *SnakeState) {
+		ss.Rules = rules
+	}
+}
+
+// Play on the board of a level, the board size and
+// start of the snake come from the level
+func WithLevel(level *Level) SnakeOption {
+	return func(ss *SnakeState) {
+		ss.Level = level
+		if level == nil {
+			return
+		}
+		ss.Height = level.Height
+		ss.Width = level.Width
+		ss.Direction = level.StartDirection
+		ss.SnakeBody = []SnakePart{{level.Start, _HEAD_TYPE_FROM_DIR[level.StartDirection]}}
+		ss.obstacles = level_obstacles(level)
+	}
+}
+
+// Returns the cells of the level's obstacles, nil for no level
+func level_obstacles(level *Level) map[Point]bool {
+	if level == nil {
+		return nil
+	}
+	obstacles := make(map[Point]bool, len(level.Obstacles))
+	for _, p := range level.Obstacles {
+		obstacles[p] = true
+	}
+	return obstacles
 }
 
-func CreateSnake(height, width int) *SnakeState {
+// Start the game with a number of lives other than the one in the rules,
+// e.g. the lives left from the previous level of a campaign
+func WithLives(lives int) SnakeOption {
+	return func(ss *SnakeState) {
+		ss.Lives = lives
+	}
+}
+
+func CreateSnake(height, width int, opts ...SnakeOption) *SnakeState {
 	snake_body := make([]SnakePart, 1)
 	// Init the snake at center of the board
 	snake_body[0] = SnakePart{
 		Point{width / 2, height / 2},
 		BODY_PART_HEAD_DOWN}
 
-	return &SnakeState{
+	ss := &SnakeState{
 		snake_body,
 		// snake is moving down at the start of the game
 		DOWN,
 		// -1, -1 represents no apple on board
 		Point{-1, -1},
+		0,
+		// no items
+		nil,
 
 		// board size
 		height,
 		width,
 
+		// default rules
+		Rules{},
+
+		// empty board
+		nil,
+		nil,
+
 		// game over
 		false,
+		// game won
+		false,
 
 		// game score
 		0,
+		ScoreBreakdown{},
+		0,
+
+		// lives, set from the rules if not set by WithLives
+		0,
+		0,
+
+		// growth and effects of items
+		0,
+		0,
+		0,
+		0,
+
+		// random seed, could be overridden by WithSeed
+		rand.Uint64(),
+		nil,
+		nil,
+	}
+	for _, opt := range opts {
+		opt(ss)
+	}
+	if ss.Lives == 0 {
+		ss.Lives = max(ss.Rules.Lives, 1)
 	}
+	ss.rng_src = rand.NewPCG(ss.Seed, ss.Seed)
+	ss.rng = rand.New(ss.rng_src)
+	return ss
 }
 
 func (ss *SnakeState) HasApple() bool {
-	return ss.Apple.X > 0 && ss.Apple.Y > 0
+	return ss.Apple.X >= 0 && ss.Apple.Y >= 0
+}
+
+// Returns the area the snake can move in, from min inclusive to max exclusive.
+// Row 0, height -1 and col 0, width -1 are saved for boarders, unless
+// the board wraps around
+func (ss *SnakeState) play_area() (Point, Point) {
+	if ss.Rules.Wrap {
+		return Point{0, 0}, Point{ss.Width, ss.Height}
+	}
+	return Point{1, 1}, Point{ss.Width - 1, ss.Height - 1}
+}
+
+// Bring a point that went over an edge of the play area
+// back from the opposite edge
+func (ss *SnakeState) wrap(p Point) Point {
+	lo, hi := ss.play_area()
+	p.X = lo.X + mod(p.X-lo.X, hi.X-lo.X)
+	p.Y = lo.Y + mod(p.Y-lo.Y, hi.Y-lo.Y)
+	return p
+}
+
+// Returns p moved across the edges of the play area so that it is next
+// to ref, when p and ref are adjacent only by wrapping around the board
+func (ss *SnakeState) unwrap(p, ref Point) Point {
+	lo, hi := ss.play_area()
+	if p.X-ref.X > 1 {
+		p.X -= hi.X - lo.X
+	} else if ref.X-p.X > 1 {
+		p.X += hi.X - lo.X
+	}
+	if p.Y-ref.Y > 1 {
+		p.Y -= hi.Y - lo.Y
+	} else if ref.Y-p.Y > 1 {
+		p.Y += hi.Y - lo.Y
+	}
+	return p
+}
+
+// Modulo that is never negative
+func mod(a, b int) int {
+	return (a%b + b) % b
 }
 
 // Update the snake's direction
@@ -112,17 +334,59 @@ func (ss *SnakeState) UpdateDirection(dir int) {
 	}
 }
 
-// Create apple if does not exist
-func (ss *SnakeState) maybe_create_apple() {
-	if ss.Apple.X > 0 && ss.Apple.Y > 0 {
+// Create apple if does not exist.
+// Returns false if there is no free cell left to put the apple
+func (ss *SnakeState) maybe_create_apple() bool {
+	if ss.HasApple() {
 		// Apple already exist
-		return
+		return true
+	}
+	free := ss.free_cells()
+	if len(free) == 0 {
+		return false
 	}
-	// row 0, height -1 and col 0, width -1
-	// are saved for boarders
-	x := rand.Intn(ss.Width-2) + 1
-	y := rand.Intn(ss.Height-2) + 1
-	ss.Apple = Point{x, y}
+	// Keep the apple off the items, unless they are all that is left
+	cells := []Point{}
+	for _, p := range free {
+		if _, taken := ss.ItemAt(p); !taken {
+			cells = append(cells, p)
+		}
+	}
+	if len(cells) == 0 {
+		ss.Items = nil
+		cells = free
+	}
+	ss.Apple = cells[ss.rng.IntN(len(cells))]
+	ss.AppleTicks = ss.AppleCountdown()
+	return true
+}
+
+// Returns the cells of the play area that are not covered by the snake
+// or obstacles
+func (ss *SnakeState) free_cells() []Point {
+	return ss.cells_free_of(ss.SnakeBody)
+}
+
+// Returns the cells of the play area that are not covered by
+// any of the bodies or obstacles
+func (ss *SnakeState) cells_free_of(bodies ...[]SnakePart) []Point {
+	occupied := map[Point]bool{}
+	for _, body := range bodies {
+		for _, part := range body {
+			occupied[part.Cord] = true
+		}
+	}
+	lo, hi := ss.play_area()
+	free := make([]Point, 0, (hi.X-lo.X)*(hi.Y-lo.Y))
+	for y := lo.Y; y < hi.Y; y++ {
+		for x := lo.X; x < hi.X; x++ {
+			p := Point{x, y}
+			if !occupied[p] && !ss.obstacles[p] {
+				free = append(free, p)
+			}
+		}
+	}
+	return free
 }
 
 // Advance snake one tick
@@ -130,15 +394,38 @@ func (ss *SnakeState) Tick() {
 	if ss.GameOver {
 		return
 	}
+	if ss.Respawning > 0 {
+		// wait for the player to see where the snake is back
+		ss.Respawning -= 1
+		return
+	}
 	new_head := ss.advance_snake_head()
+	// An invincible snake goes through obstacles and itself, not the boarder
 	touched := ss.snake_touched(new_head.Cord)
-	if touched {
-		ss.GameOver = true
+	if touched && (!ss.Invincible() || ss.off_board(new_head.Cord)) {
+		if ss.Lives > 1 {
+			ss.lose_life()
+		} else {
+			ss.Lives = 0
+			ss.GameOver = true
+		}
 		return
 	}
 
 	ss.maybe_consume_apple_and_grow_snake(new_head)
-	ss.maybe_create_apple()
+	if ss.Rules.TimedApples {
+		ss.update_apple_countdown()
+	}
+	if ss.Rules.Items {
+		ss.maybe_consume_item(new_head.Cord)
+		ss.update_items()
+	}
+	if !ss.maybe_create_apple() {
+		// The snake filled the board, nothing left to eat,
+		// the player wins
+		ss.GameOver = true
+		ss.GameWon = true
+	}
 }
 
 // Advance snake head by one cell, return the new snake head
@@ -147,37 +434,38 @@ func (ss *SnakeState) advance_snake_head() SnakePart {
 	old_head := ss.SnakeBody[head_idx]
 	new_head := old_head
 	new_head.PartType = _HEAD_TYPE_FROM_DIR[ss.Direction]
-	switch ss.Direction {
-	case UP:
-		new_head.Cord.Y -= 1
-	case DOWN:
-		new_head.Cord.Y += 1
-	case LEFT:
-		new_head.Cord.X -= 1
-	case RIGHT:
-		new_head.Cord.X += 1
+	new_head.Cord = old_head.Cord.Next(ss.Direction)
+	if ss.Rules.Wrap {
+		new_head.Cord = ss.wrap(new_head.Cord)
 	}
 	return new_head
 }
 
-// Returns true if new head touches boarder or snake itself
+// Returns true if new head touches boarder, obstacles or snake itself
 func (ss *SnakeState) snake_touched(new_head Point) bool {
-	if new_head.X <= 0 || new_head.X >= ss.Width-1 {
-		return true
-	}
-	if new_head.Y <= 0 || new_head.Y >= ss.Height-1 {
+	if ss.hits_wall(new_head) {
 		return true
 	}
 	// Check if touch itself.
-	// Don't check tail
-	for i := 1; i < len(ss.SnakeBody); i++ {
-		body := ss.SnakeBody[i].Cord
-		if new_head.X == body.X && new_head.Y == body.Y {
-			return true
-		}
-	}
+	// Don't check the tail if it moves away in the same tick
+	return body_covers(ss.SnakeBody, new_head, ss.tail_moves(new_head))
+}
+
+// True if p is on the boarder, an obstacle or outside of the board
+func (ss *SnakeState) hits_wall(p Point) bool {
+	return ss.off_board(p) || ss.obstacles[p]
+}
 
-	return false
+// True if p is on the boarder or outside of the board
+func (ss *SnakeState) off_board(p Point) bool {
+	lo, hi := ss.play_area()
+	return p.X < lo.X || p.X >= hi.X || p.Y < lo.Y || p.Y >= hi.Y
+}
+
+// True if the tail moves away in the tick moving the head to new_head,
+// it stays while the snake eats the apple or grows from an item
+func (ss *SnakeState) tail_moves(new_head Point) bool {
+	return new_head != ss.Apple && ss.Grow == 0
 }
 
 func (ss *SnakeState) maybe_consume_apple_and_grow_snake(new_head SnakePart) {
@@ -186,32 +474,41 @@ func (ss *SnakeState) maybe_consume_apple_and_grow_snake(new_head SnakePart) {
 	if new_head.Cord == ss.Apple {
 		// consume apple
 		ss.Apple = Point{-1, -1}
-		ss.Score += 1
+		ss.score_apple()
+	} else if ss.Grow > 0 {
+		// keep the tail
+		ss.Grow -= 1
 	} else {
 		// cut off tail, this will make the snake move one cell
 		ss.SnakeBody = ss.SnakeBody[1:]
 	}
+	ss.update_part_types(ss.SnakeBody)
+}
 
-	if len(ss.SnakeBody) > 1 {
+// Update the part types of a body that just moved one cell
+func (ss *SnakeState) update_part_types(body []SnakePart) {
+	if len(body) > 1 {
 		// More than 1 body, the last one is tail
-		tail_type, err := get_tail_type(ss.SnakeBody[0].Cord, ss.SnakeBody[1].Cord)
+		tail := body[0].Cord
+		tail_type, err := get_tail_type(tail, ss.unwrap(body[1].Cord, tail))
 		if err == nil {
-			ss.SnakeBody[0].PartType = tail_type
+			body[0].PartType = tail_type
 		} else {
 			log.Fatal(err)
 		}
 	}
 
-	if len(ss.SnakeBody) > 2 {
+	if len(body) > 2 {
 		// More than 2, there might be turns, only need to
 		// update the body type of the old head, that's where
 		// the turn happens
+		old_head := body[len(body)-2].Cord
 		t, err := get_part_type(
-			ss.SnakeBody[len(ss.SnakeBody)-1].Cord,
-			ss.SnakeBody[len(ss.SnakeBody)-2].Cord,
-			ss.SnakeBody[len(ss.SnakeBody)-3].Cord)
+			ss.unwrap(body[len(body)-1].Cord, old_head),
+			old_head,
+			ss.unwrap(body[len(body)-3].Cord, old_head))
 		if err == nil {
-			ss.SnakeBody[len(ss.SnakeBody)-2].PartType = t
+			body[len(body)-2].PartType = t
 		} else {
 			log.Fatal(err)
 		}
@@ -49,6 +49,82 @@ func TestCreateApple(t *testing.T) {
 	assert.Less(t, snake_state.Apple.Y, snake_state.Height)
 }
 
+// Play a game with a fixed sequence of directions, record the apple
+// location after every tick and the tick the game ended
+func play_seeded_game(seed uint64, dirs []int) ([]Point, int, int) {
+	ss := CreateSnake(10, 10, WithSeed(seed))
+	apples := []Point{}
+	for tick := 0; tick < 200; tick++ {
+		ss.UpdateDirection(dirs[tick%len(dirs)])
+		ss.Tick()
+		apples = append(apples, ss.Apple)
+		if ss.GameOver {
+			return apples, ss.Score, tick
+		}
+	}
+	return apples, ss.Score, -1
+}
+
+func TestSeededGame(t *testing.T) {
+	ss := CreateSnake(10, 10, WithSeed(42))
+	assert.Equal(t, uint64(42), ss.Seed)
+
+	// Circle around the center of the board
+	dirs := []int{LEFT, LEFT, UP, UP, RIGHT, RIGHT, DOWN, DOWN}
+	apples1, score1, over1 := play_seeded_game(42, dirs)
+	apples2, score2, over2 := play_seeded_game(42, dirs)
+	assert.Equal(t, apples1, apples2)
+	assert.Equal(t, score1, score2)
+	assert.Equal(t, over1, over2)
+
+	// A different seed places apples differently
+	apples3, _, _ := play_seeded_game(43, dirs)
+	assert.NotEqual(t, apples1, apples3)
+}
+
+func TestCreateAppleOnFreeCell(t *testing.T) {
+	// 4x4 board has 4 cells inside the boarder, the snake covers 3 of them
+	for seed := uint64(0); seed < 50; seed++ {
+		ss := CreateSnake(4, 4, WithSeed(seed))
+		ss.SnakeBody = []SnakePart{
+			make_tail(1, 1, BODY_PART_TAIL_RIGHT),
+			make_body(2, 1),
+			make_head(2, 2, BODY_PART_HEAD_DOWN)}
+		assert.True(t, ss.maybe_create_apple())
+		// The only free cell
+		assert.Equal(t, Point{1, 2}, ss.Apple)
+	}
+
+	// Board is full, no apple can be created
+	ss := CreateSnake(4, 4)
+	ss.SnakeBody = []SnakePart{
+		make_tail(1, 1, BODY_PART_TAIL_RIGHT),
+		make_body(2, 1),
+		make_body(2, 2),
+		make_head(1, 2, BODY_PART_HEAD_LEFT)}
+	assert.Empty(t, ss.free_cells())
+	assert.False(t, ss.maybe_create_apple())
+	assert.False(t, ss.HasApple())
+}
+
+func TestFillBoard(t *testing.T) {
+	ss := CreateSnake(4, 4)
+	ss.SnakeBody = []SnakePart{
+		make_tail(1, 1, BODY_PART_TAIL_RIGHT),
+		make_body(2, 1),
+		make_head(2, 2, BODY_PART_HEAD_DOWN)}
+	ss.Direction = LEFT
+	ss.Apple = Point{1, 2}
+
+	// Eating the last apple fills the board and ends the game
+	ss.Tick()
+	assert.Equal(t, 1, ss.Score)
+	assert.Len(t, ss.SnakeBody, 4)
+	assert.True(t, ss.GameOver)
+	assert.True(t, ss.GameWon)
+	assert.False(t, ss.HasApple())
+}
+
 func make_head(x, y, part int) SnakePart {
 	return SnakePart{
 		Point{x, y},
@@ -164,6 +240,7 @@ func TestTick(t *testing.T) {
 	}
 	// Should touched wall
 	assert.True(t, ss.GameOver)
+	assert.False(t, ss.GameWon)
 	// Snake should remain at the location before touching the wall
 	assert.Equal(t, make_head(0, 6, BODY_PART_HEAD_LEFT), ss.advance_snake_head())
 }
@@ -201,3 +278,49 @@ func TestGetBodyPart(t *testing.T) {
 		Point{4, 5}, Point{5, 5}, Point{5, 4},
 		BODY_PART_BODY_L3)
 }
+
+func TestWrap(t *testing.T) {
+	ss := CreateSnake(10, 10, WithRules(Rules{Wrap: true}))
+	// No boarder, every cell of the board is free
+	assert.Len(t, ss.free_cells(), 10*10-1)
+	assert.False(t, ss.snake_touched(Point{0, 5}))
+	assert.False(t, ss.snake_touched(Point{9, 9}))
+	assert.True(t, ss.snake_touched(Point{-1, 5}))
+
+	ss.Apple = Point{0, 0}
+	assert.True(t, ss.HasApple())
+
+	ss.SnakeBody = []SnakePart{
+		make_tail(3, 5, BODY_PART_TAIL_LEFT),
+		make_body(2, 5),
+		make_head(1, 5, BODY_PART_HEAD_LEFT)}
+	ss.Direction = LEFT
+	ss.Tick()
+	ss.Tick()
+	// Went over the left edge and came back from the right
+	assert.Equal(t, []SnakePart{
+		make_tail(1, 5, BODY_PART_TAIL_LEFT),
+		{Point{0, 5}, BODY_PART_H},
+		make_head(9, 5, BODY_PART_HEAD_LEFT)}, ss.SnakeBody)
+
+	// Turn right after the edge
+	ss.UpdateDirection(UP)
+	ss.Tick()
+	corner, _ := get_part_type(Point{9, 4}, Point{9, 5}, Point{10, 5})
+	assert.Equal(t, []SnakePart{
+		make_tail(0, 5, BODY_PART_TAIL_LEFT),
+		{Point{9, 5}, corner},
+		make_head(9, 4, BODY_PART_HEAD_UP)}, ss.SnakeBody)
+
+	// Tail follows the head over the edge
+	ss.Tick()
+	assert.Equal(t, make_tail(9, 5, BODY_PART_TAIL_UP), ss.SnakeBody[0])
+	assert.False(t, ss.GameOver)
+
+	// Over the top edge
+	for i := 0; i < 4; i++ {
+		ss.Tick()
+	}
+	assert.Equal(t, Point{9, 9}, ss.SnakeBody[len(ss.SnakeBody)-1].Cord)
+	assert.False(t, ss.GameOver)
+}
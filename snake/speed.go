@@ -0,0 +1,85 @@
+package snake
+
+import (
+	"fmt"
+	"math"
+	"time"
+)
+
+const (
+	// Each apple eaten makes the interval this much shorter
+	// when the speed is progressive
+	SPEED_UP_FACTOR = 0.97
+
+	// The snake never moves faster than this
+	MIN_TICK_INTERVAL = 50 * time.Millisecond
+)
+
+// A named speed to choose from
+type Difficulty struct {
+	Name     string
+	Interval time.Duration
+}
+
+var DIFFICULTIES = []Difficulty{
+	{"easy", 250 * time.Millisecond},
+	{"normal", 200 * time.Millisecond},
+	{"hard", 120 * time.Millisecond},
+	{"insane", 70 * time.Millisecond},
+}
+
+// Returns the difficulty with the name
+func FindDifficulty(name string) (Difficulty, error) {
+	for _, d := range DIFFICULTIES {
+		if d.Name == name {
+			return d, nil
+		}
+	}
+	return Difficulty{}, fmt.Errorf("unknown difficulty %q", name)
+}
+
+// How fast the snake moves
+type Speed struct {
+	// Time between two ticks at the start of the game
+	Interval time.Duration
+
+	// If true the snake gets faster with every apple eaten
+	Progressive bool
+}
+
+// 5 moves per second
+var DEFAULT_SPEED = Speed{200 * time.Millisecond, false}
+
+// Returns the time between two ticks at the score
+func (s Speed) TickInterval(score int) time.Duration {
+	interval := s.Interval
+	if s.Progressive {
+		interval = time.Duration(float64(interval) * math.Pow(SPEED_UP_FACTOR, float64(score)))
+	}
+	return max(interval, MIN_TICK_INTERVAL)
+}
+
+// Turns the frames of a game into ticks. Time is counted in frames
+// so nothing is lost to rounding, and ticks do not drift however
+// the interval and the frame rate line up
+type Ticker struct {
+	// Elapsed time multiplied by the frame rate
+	Elapsed time.Duration `json:"elapsed"`
+}
+
+// Advance one frame of a game updating tps times per second,
+// returns the number of ticks due
+func (t *Ticker) Frame(tps int, interval time.Duration) int {
+	t.Elapsed += time.Second
+	due := interval * time.Duration(tps)
+	ticks := 0
+	for t.Elapsed >= due {
+		t.Elapsed -= due
+		ticks += 1
+	}
+	return ticks
+}
+
+func (t *Ticker) Reset() {
+	t.Elapsed = 0
+}
@@ -0,0 +1,48 @@
+package snake
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTickInterval(t *testing.T) {
+	assert.Equal(t, 200*time.Millisecond, DEFAULT_SPEED.TickInterval(0))
+	assert.Equal(t, 200*time.Millisecond, DEFAULT_SPEED.TickInterval(30))
+
+	speed := Speed{200 * time.Millisecond, true}
+	assert.Equal(t, 200*time.Millisecond, speed.TickInterval(0))
+	assert.Equal(t, 194*time.Millisecond, speed.TickInterval(1))
+	assert.Less(t, speed.TickInterval(10), speed.TickInterval(5))
+	// Never faster than the limit
+	assert.Equal(t, MIN_TICK_INTERVAL, speed.TickInterval(1000))
+}
+
+func TestFindDifficulty(t *testing.T) {
+	d, err := FindDifficulty("hard")
+	assert.NoError(t, err)
+	assert.Equal(t, 120*time.Millisecond, d.Interval)
+
+	_, err = FindDifficulty("impossible")
+	assert.Error(t, err)
+}
+
+func TestTicker(t *testing.T) {
+	ticker := Ticker{}
+	interval := 70 * time.Millisecond
+
+	// 70ms is not a multiple of a 60 FPS frame, but after 7 seconds
+	// exactly 100 ticks are played
+	ticks := 0
+	for i := 0; i < 7*60; i++ {
+		ticks += ticker.Frame(60, interval)
+	}
+	assert.Equal(t, 100, ticks)
+
+	// Frames longer than the interval play several ticks
+	ticker.Reset()
+	assert.Equal(t, 1, ticker.Frame(10, interval))
+	assert.Equal(t, 1, ticker.Frame(10, interval))
+	assert.Equal(t, 2, ticker.Frame(10, interval))
+}
@@ -0,0 +1,79 @@
+package snake
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+// Statistics of the games played, kept between runs next to the high scores
+type GameStats struct {
+	// Number of finished games
+	Played int `json:"played"`
+
+	// Games won by filling the board or finishing a campaign
+	Won int `json:"won"`
+
+	// Games ended by touching the boarder or the snake itself
+	Died int `json:"died"`
+
+	// Best score of all finished games
+	BestScore int `json:"best_score"`
+}
+
+// Record a finished game, wins and deaths are counted separately
+func (st *GameStats) Record(ss *SnakeState) {
+	if !ss.GameOver {
+		return
+	}
+	st.record(ss.GameWon, ss.Score)
+}
+
+// Record a finished campaign as a won game with its total score
+func (st *GameStats) RecordCampaign(c *Campaign) {
+	st.record(true, c.TotalScore)
+}
+
+func (st *GameStats) record(won bool, score int) {
+	st.Played += 1
+	if won {
+		st.Won += 1
+	} else {
+		st.Died += 1
+	}
+	st.BestScore = max(st.BestScore, score)
+}
+
+// Returns the default file for the stats
+func DefaultStatsPath() (string, error) {
+	dir, err := ConfigDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "stats.json"), nil
+}
+
+// Load the stats, no file means no games played yet
+func LoadStats(path string) (GameStats, error) {
+	stats := GameStats{}
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return stats, nil
+	} else if err != nil {
+		return stats, err
+	}
+	if err := json.Unmarshal(data, &stats); err != nil {
+		return GameStats{}, fmt.Errorf("invalid stats %s: %w", path, err)
+	}
+	return stats, nil
+}
+
+func SaveStats(path string, stats GameStats) error {
+	data, err := json.Marshal(stats)
+	if err != nil {
+		return err
+	}
+	return WriteFileAtomic(path, data, 0644)
+}
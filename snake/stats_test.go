@@ -0,0 +1,53 @@
+package snake
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGameStats(t *testing.T) {
+	stats := GameStats{}
+
+	// Game in progress is not recorded
+	ss := CreateSnake(10, 10)
+	stats.Record(ss)
+	assert.Equal(t, GameStats{}, stats)
+
+	ss.GameOver = true
+	ss.Score = 3
+	stats.Record(ss)
+	assert.Equal(t, GameStats{1, 0, 1, 3}, stats)
+
+	ss = CreateSnake(4, 4)
+	ss.GameOver = true
+	ss.GameWon = true
+	ss.Score = 2
+	stats.Record(ss)
+	assert.Equal(t, GameStats{2, 1, 1, 3}, stats)
+
+	// A finished campaign is a win with its total score
+	stats.RecordCampaign(&Campaign{TotalScore: 25})
+	assert.Equal(t, GameStats{3, 2, 1, 25}, stats)
+}
+
+func TestStatsFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "stats.json")
+
+	// Nothing played yet
+	stats, err := LoadStats(path)
+	assert.NoError(t, err)
+	assert.Equal(t, GameStats{}, stats)
+
+	stats = GameStats{5, 1, 4, 30}
+	assert.NoError(t, SaveStats(path, stats))
+	loaded, err := LoadStats(path)
+	assert.NoError(t, err)
+	assert.Equal(t, stats, loaded)
+
+	os.WriteFile(path, []byte("{"), 0644)
+	_, err = LoadStats(path)
+	assert.ErrorContains(t, err, "invalid stats")
+}
@@ -0,0 +1,219 @@
+package snake
+
+import "errors"
+
+// Number of snakes in a versus game
+const VERSUS_PLAYERS = 2
+
+// One of the snakes of a versus game
+type VersusSnake struct {
+	// SnakeBody[0] is the tail, SnakeBody[-1] is the head
+	SnakeBody []SnakePart
+
+	// Moving direction of the snake
+	Direction int
+
+	// Number of apples ate
+	Score int
+
+	// True if the snake ran into a wall, a snake or the other head
+	Dead bool
+}
+
+func (s *VersusSnake) Head() Point {
+	return s.SnakeBody[len(s.SnakeBody)-1].Cord
+}
+
+// Two snakes on the same board going for the same apple.
+// The game is over as soon as a snake dies, the other one wins
+type VersusState struct {
+	Snakes [VERSUS_PLAYERS]VersusSnake
+
+	// Board size, rules, apple and random source shared by the snakes,
+	// its own snake body is not used
+	Board *SnakeState
+
+	// True if game over
+	GameOver bool
+
+	// Index of the snake that won, -1 for a draw
+	Winner int
+}
+
+// Returns an error if the rules do not apply to versus games, which only
+// know the Wrap rule
+func CheckVersusRules(rules Rules) error {
+	if rules.Lives > 1 || rules.Items || rules.TimedApples {
+		return errors.New("lives, items and timed apples do not apply to versus games")
+	}
+	return nil
+}
+
+// Create a versus game on an empty board, only the Wrap rule applies.
+// The snakes start on the left and right of the board, moving up
+func CreateVersus(height, width int, opts ...SnakeOption) *VersusState {
+	board := CreateSnake(height, width, opts...)
+	board.SnakeBody = nil
+	vs := &VersusState{
+		[VERSUS_PLAYERS]VersusSnake{},
+		board,
+		// game over
+		false,
+		// no winner
+		-1,
+	}
+	starts := [VERSUS_PLAYERS]Point{{width / 4, height / 2}, {width - 1 - width/4, height / 2}}
+	for i, start := range starts {
+		vs.Snakes[i] = VersusSnake{[]SnakePart{{start, BODY_PART_HEAD_UP}}, UP, 0, false}
+	}
+	return vs
+}
+
+// Update the direction of a player's snake, the snake cannot reverse
+func (vs *VersusState) UpdateDirection(player, dir int) {
+	s := &vs.Snakes[player]
+	if dir != s.Direction && dir != Opposite(s.Direction) {
+		s.Direction = dir
+	}
+}
+
+// Advance both snakes one tick.
+// The snakes move at the same time. A snake dies if its head runs into a
+// wall, a snake as it is after the move, e.g. not a tail moving away, or the
+// head of the other snake, in which case both die. The tail of a snake
+// dying in the tick does not move away
+func (vs *VersusState) Tick() {
+	if vs.GameOver {
+		return
+	}
+	board := vs.Board
+	var heads [VERSUS_PLAYERS]SnakePart
+	var grows [VERSUS_PLAYERS]bool
+	for i := range vs.Snakes {
+		s := &vs.Snakes[i]
+		head := s.SnakeBody[len(s.SnakeBody)-1]
+		head.PartType = _HEAD_TYPE_FROM_DIR[s.Direction]
+		head.Cord = head.Cord.Next(s.Direction)
+		if board.Rules.Wrap {
+			head.Cord = board.wrap(head.Cord)
+		}
+		heads[i] = head
+		grows[i] = head.Cord == board.Apple
+	}
+
+	// A tail moves away unless its snake eats the apple or dies. The snakes
+	// are first taken to survive, until no more tails stay because of deaths
+	for changed := true; changed; {
+		changed = false
+		for i := range vs.Snakes {
+			if vs.Snakes[i].Dead {
+				continue
+			}
+			touched := board.hits_wall(heads[i].Cord)
+			for j := range vs.Snakes {
+				tail_moves := !grows[j] && !vs.Snakes[j].Dead
+				touched = touched || body_covers(vs.Snakes[j].SnakeBody, heads[i].Cord, tail_moves)
+				touched = touched || (j != i && heads[j].Cord == heads[i].Cord)
+			}
+			if touched {
+				vs.Snakes[i].Dead = true
+				changed = true
+			}
+		}
+	}
+
+	alive := []int{}
+	for i := range vs.Snakes {
+		s := &vs.Snakes[i]
+		if s.Dead {
+			continue
+		}
+		alive = append(alive, i)
+		s.SnakeBody = append(s.SnakeBody, heads[i])
+		if grows[i] {
+			s.Score += 1
+			board.Apple = Point{-1, -1}
+		} else {
+			s.SnakeBody = s.SnakeBody[1:]
+		}
+		board.update_part_types(s.SnakeBody)
+	}
+
+	if len(alive) < VERSUS_PLAYERS {
+		vs.GameOver = true
+		if len(alive) == 1 {
+			vs.Winner = alive[0]
+		}
+		return
+	}
+	if !vs.maybe_create_apple() {
+		// The snakes filled the board, the one with more apples wins
+		vs.GameOver = true
+		vs.Winner = vs.leader()
+	}
+}
+
+// End the game with the player's snake dead, e.g. when the player left
+func (vs *VersusState) Resign(player int) {
+	if vs.GameOver {
+		return
+	}
+	vs.Snakes[player].Dead = true
+	vs.GameOver = true
+	alive := []int{}
+	for i, s := range vs.Snakes {
+		if !s.Dead {
+			alive = append(alive, i)
+		}
+	}
+	if len(alive) == 1 {
+		vs.Winner = alive[0]
+	}
+}
+
+// True if p is covered by the body, the tail is not counted
+// if it moves away in this tick
+func body_covers(body []SnakePart, p Point, tail_moves bool) bool {
+	start := 0
+	if tail_moves && len(body) > 0 {
+		start = 1
+	}
+	for _, part := range body[start:] {
+		if part.Cord == p {
+			return true
+		}
+	}
+	return false
+}
+
+// Create apple if does not exist.
+// Returns false if there is no free cell left to put the apple
+func (vs *VersusState) maybe_create_apple() bool {
+	board := vs.Board
+	if board.HasApple() {
+		return true
+	}
+	bodies := [][]SnakePart{}
+	for _, s := range vs.Snakes {
+		bodies = append(bodies, s.SnakeBody)
+	}
+	free := board.cells_free_of(bodies...)
+	if len(free) == 0 {
+		return false
+	}
+	board.Apple = free[board.rng.IntN(len(free))]
+	return true
+}
+
+// Returns the index of the snake with the highest score, -1 for a tie
+func (vs *VersusState) leader() int {
+	leader, best := -1, -1
+	for i, s := range vs.Snakes {
+		if s.Score > best {
+			leader, best = i, s.Score
+		} else if s.Score == best {
+			leader = -1
+		}
+	}
+	return leader
+}
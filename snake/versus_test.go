@@ -0,0 +1,176 @@
+package snake
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCreateVersus(t *testing.T) {
+	vs := CreateVersus(20, 20, WithSeed(1))
+	assert.Equal(t, Point{5, 10}, vs.Snakes[0].Head())
+	assert.Equal(t, Point{14, 10}, vs.Snakes[1].Head())
+	assert.Equal(t, -1, vs.Winner)
+
+	// Cannot reverse
+	vs.UpdateDirection(0, DOWN)
+	vs.UpdateDirection(1, LEFT)
+	assert.Equal(t, UP, vs.Snakes[0].Direction)
+	assert.Equal(t, LEFT, vs.Snakes[1].Direction)
+
+	vs.Tick()
+	assert.False(t, vs.GameOver)
+	assert.Equal(t, Point{5, 9}, vs.Snakes[0].Head())
+	assert.Equal(t, Point{13, 10}, vs.Snakes[1].Head())
+	assert.True(t, vs.Board.HasApple())
+}
+
+func TestVersusApple(t *testing.T) {
+	vs := CreateVersus(20, 20, WithSeed(1))
+	vs.Board.Apple = Point{5, 9}
+	vs.Tick()
+	assert.Equal(t, 1, vs.Snakes[0].Score)
+	assert.Equal(t, 0, vs.Snakes[1].Score)
+	assert.Len(t, vs.Snakes[0].SnakeBody, 2)
+	assert.Equal(t, make_tail(5, 10, BODY_PART_TAIL_UP), vs.Snakes[0].SnakeBody[0])
+	assert.NotEqual(t, Point{5, 9}, vs.Board.Apple)
+}
+
+// Two snakes of length 2 facing each other on a row
+func create_facing_snakes(gap int) *VersusState {
+	vs := CreateVersus(20, 20, WithSeed(1))
+	vs.Snakes[0].SnakeBody = []SnakePart{
+		make_tail(3, 5, BODY_PART_TAIL_RIGHT),
+		make_head(4, 5, BODY_PART_HEAD_RIGHT)}
+	vs.Snakes[0].Direction = RIGHT
+	vs.Snakes[1].SnakeBody = []SnakePart{
+		make_tail(6+gap, 5, BODY_PART_TAIL_LEFT),
+		make_head(5+gap, 5, BODY_PART_HEAD_LEFT)}
+	vs.Snakes[1].Direction = LEFT
+	vs.Board.Apple = Point{1, 1}
+	return vs
+}
+
+func TestVersusHeadToHead(t *testing.T) {
+	// Both heads move to the same cell
+	vs := create_facing_snakes(1)
+	vs.Tick()
+	assert.True(t, vs.GameOver)
+	assert.True(t, vs.Snakes[0].Dead)
+	assert.True(t, vs.Snakes[1].Dead)
+	assert.Equal(t, -1, vs.Winner)
+
+	// The heads swap cells
+	vs = create_facing_snakes(0)
+	vs.Tick()
+	assert.True(t, vs.Snakes[0].Dead)
+	assert.True(t, vs.Snakes[1].Dead)
+	assert.Equal(t, -1, vs.Winner)
+}
+
+func TestVersusHeadToDyingTail(t *testing.T) {
+	// Snake 1 runs into the boarder, snake 0 into its tail
+	vs := create_facing_snakes(0)
+	vs.Snakes[1].SnakeBody = []SnakePart{
+		make_tail(3, 5, BODY_PART_TAIL_LEFT),
+		make_body(2, 5),
+		make_head(1, 5, BODY_PART_HEAD_LEFT)}
+	vs.Snakes[0].SnakeBody = []SnakePart{
+		make_tail(5, 5, BODY_PART_TAIL_LEFT),
+		make_head(4, 5, BODY_PART_HEAD_LEFT)}
+	vs.Snakes[0].Direction = LEFT
+	vs.Tick()
+	// The tail of the dead snake stays
+	assert.True(t, vs.Snakes[1].Dead)
+	assert.True(t, vs.Snakes[0].Dead)
+	assert.Equal(t, -1, vs.Winner)
+}
+
+func TestVersusHeadToBody(t *testing.T) {
+	// Snake 1 turns away, snake 0 runs into its body
+	vs := create_facing_snakes(1)
+	vs.Snakes[1].SnakeBody = []SnakePart{
+		make_tail(6, 4, BODY_PART_TAIL_DOWN),
+		make_body(6, 5),
+		make_head(6, 6, BODY_PART_HEAD_DOWN)}
+	vs.Snakes[1].Direction = DOWN
+	vs.Snakes[0].SnakeBody = []SnakePart{make_head(5, 5, BODY_PART_HEAD_RIGHT)}
+	vs.Tick()
+	assert.True(t, vs.GameOver)
+	assert.True(t, vs.Snakes[0].Dead)
+	assert.False(t, vs.Snakes[1].Dead)
+	assert.Equal(t, 1, vs.Winner)
+	assert.Equal(t, Point{6, 7}, vs.Snakes[1].Head())
+}
+
+func TestVersusTail(t *testing.T) {
+	// Snake 0 follows the tail of snake 1, which moves away
+	vs := create_facing_snakes(0)
+	vs.Snakes[1].SnakeBody = []SnakePart{
+		make_tail(5, 5, BODY_PART_TAIL_DOWN),
+		make_head(5, 6, BODY_PART_HEAD_DOWN)}
+	vs.Snakes[1].Direction = DOWN
+	vs.Tick()
+	assert.False(t, vs.GameOver)
+	assert.Equal(t, Point{5, 5}, vs.Snakes[0].Head())
+
+	// The tail stays if the snake eats the apple
+	vs = create_facing_snakes(0)
+	vs.Snakes[1].SnakeBody = []SnakePart{
+		make_tail(5, 5, BODY_PART_TAIL_DOWN),
+		make_head(5, 6, BODY_PART_HEAD_DOWN)}
+	vs.Snakes[1].Direction = DOWN
+	vs.Board.Apple = Point{5, 7}
+	vs.Tick()
+	assert.True(t, vs.Snakes[0].Dead)
+	assert.Equal(t, 1, vs.Winner)
+}
+
+func TestVersusWall(t *testing.T) {
+	vs := CreateVersus(10, 10, WithSeed(1))
+	vs.UpdateDirection(0, LEFT)
+	for i := 0; i < 3 && !vs.GameOver; i++ {
+		vs.Tick()
+	}
+	assert.True(t, vs.GameOver)
+	assert.True(t, vs.Snakes[0].Dead)
+	assert.Equal(t, 1, vs.Winner)
+
+	// No walls on a board that wraps around
+	vs = CreateVersus(10, 10, WithSeed(1), WithRules(Rules{Wrap: true}))
+	vs.UpdateDirection(0, LEFT)
+	for i := 0; i < 3; i++ {
+		vs.Tick()
+	}
+	assert.False(t, vs.GameOver)
+	assert.Equal(t, Point{9, 5}, vs.Snakes[0].Head())
+}
+
+func TestVersusLeader(t *testing.T) {
+	vs := CreateVersus(10, 10)
+	assert.Equal(t, -1, vs.leader())
+	vs.Snakes[1].Score = 2
+	assert.Equal(t, 1, vs.leader())
+	vs.Snakes[0].Score = 3
+	assert.Equal(t, 0, vs.leader())
+}
+
+func TestVersusResign(t *testing.T) {
+	vs := CreateVersus(10, 10)
+	vs.Resign(0)
+	assert.True(t, vs.GameOver)
+	assert.True(t, vs.Snakes[0].Dead)
+	assert.Equal(t, 1, vs.Winner)
+
+	// Nothing changes after the game is over
+	vs.Resign(1)
+	assert.False(t, vs.Snakes[1].Dead)
+	assert.Equal(t, 1, vs.Winner)
+}
+
+func TestCheckVersusRules(t *testing.T) {
+	assert.NoError(t, CheckVersusRules(Rules{Wrap: true, Lives: 1}))
+	assert.Error(t, CheckVersusRules(Rules{Lives: 3}))
+	assert.Error(t, CheckVersusRules(Rules{Items: true}))
+	assert.Error(t, CheckVersusRules(Rules{TimedApples: true}))
+}